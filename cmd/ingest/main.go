@@ -2,19 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	pgvector "github.com/pgvector/pgvector-go"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/articles"
 	"github.com/rajeshkumarblr/hn_station/internal/content"
 	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/linkcheck"
+	"github.com/rajeshkumarblr/hn_station/internal/live"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
@@ -59,38 +65,184 @@ func main() {
 
 	log.Println("Starting Ingestion Service...")
 
+	// Backfill normalized URLs and domains for stories ingested before those columns existed
+	if n, err := store.BackfillURLKeys(ctx, 1000); err != nil {
+		log.Printf("Failed to backfill URL keys: %v", err)
+	} else if n > 0 {
+		log.Printf("Backfilled URL keys for %d stories", n)
+	}
+
 	// Run initially
 
 	// Start Summary Worker
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	summaryQueue := make(chan SummaryJob, 500) // Buffer for pending summaries
-	go startSummaryWorker(ctx, store, aiClient, apiKey, summaryQueue)
+	fetcher := content.NewFetcher(nil)
+	fetcher.Extractors = content.NewExtractors(content.GitHubFromEnv(os.Getenv, fetcher))
+	articleCache := articles.New(store, fetcher)
+	go startSummaryWorker(ctx, store, articleCache, aiClient, apiKey, summaryQueue)
+
+	// Start Preview Worker
+	go startPreviewWorker(ctx, store, articleCache)
+
+	// Start Link Health Checker
+	go startLinkChecker(ctx, store, linkcheck.New(store, fetcher))
+
+	// Start Embedding Worker
+	embedder, err := ai.NewEmbedder(os.Getenv("EMBEDDING_PROVIDER"), apiKey)
+	if err != nil {
+		log.Printf("Embedding worker disabled: %v", err)
+	} else {
+		go startEmbeddingWorker(ctx, store, embedder)
+	}
 
 	// Run initially
+	watermark := time.Now()
+	snapshot := takeLiveSnapshot(ctx, store)
 	runIngestion(ctx, client, store, aiClient, summaryQueue)
+	publishLiveEvents(ctx, store, snapshot)
+	watermark = matchWatchlists(ctx, store, watermark)
+
+	purgeDeletedAccounts(ctx, store)
 
 	// Ticker for periodic updates (every 1 minute)
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
+	purgeTicker := time.NewTicker(1 * time.Hour)
+	defer purgeTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("Shutting down ingestion service...")
 			return
 		case <-ticker.C:
+			snapshot := takeLiveSnapshot(ctx, store)
 			runIngestion(ctx, client, store, aiClient, summaryQueue)
+			publishLiveEvents(ctx, store, snapshot)
+			watermark = matchWatchlists(ctx, store, watermark)
+		case <-purgeTicker.C:
+			purgeDeletedAccounts(ctx, store)
+			deleteStaleRateLimitBuckets(ctx, store)
 		}
 	}
 }
 
+const (
+	// LiveSnapshotWindow covers the "new" list as well as ranked stories
+	LiveSnapshotWindow = 48 * time.Hour
+	LiveCommentLimit   = 2000
+)
+
+// liveSnapshot is the story state before an ingestion run, used to derive
+// live events afterwards.
+type liveSnapshot struct {
+	stories map[int64]storage.StorySnapshot
+	at      time.Time
+}
+
+func takeLiveSnapshot(ctx context.Context, store *storage.Store) *liveSnapshot {
+	stories, at, err := store.GetLiveSnapshot(ctx, LiveSnapshotWindow)
+	if err != nil {
+		log.Printf("Failed to snapshot stories for live events: %v", err)
+		return nil
+	}
+	return &liveSnapshot{stories: stories, at: at}
+}
+
+// publishLiveEvents diffs the stories against the pre-run snapshot and
+// publishes the changes, plus comments stored during the run, via NOTIFY.
+// It always bumps the ingest version and ends with an ingest_complete event,
+// which API servers use to invalidate their response caches.
+func publishLiveEvents(ctx context.Context, store *storage.Store, before *liveSnapshot) {
+	var events []live.Event
+	if before != nil {
+		events = liveEventsSince(ctx, store, before)
+	}
+
+	version, err := store.BumpIngestVersion(ctx)
+	if err != nil {
+		log.Printf("Failed to bump ingest version: %v", err)
+	} else {
+		events = append(events, live.Event{Type: live.EventIngestComplete, Version: version})
+	}
+
+	payloads, err := live.EncodePayloads(events)
+	if err != nil {
+		log.Printf("Failed to encode live events: %v", err)
+		return
+	}
+	for _, payload := range payloads {
+		if err := store.Notify(ctx, storage.LiveChannel, payload); err != nil {
+			log.Printf("Failed to publish live events: %v", err)
+			return
+		}
+	}
+	if len(events) > 0 {
+		log.Printf("Published %d live events", len(events))
+	}
+}
+
+func liveEventsSince(ctx context.Context, store *storage.Store, before *liveSnapshot) []live.Event {
+	after, _, err := store.GetLiveSnapshot(ctx, LiveSnapshotWindow)
+	if err != nil {
+		log.Printf("Failed to snapshot stories for live events: %v", err)
+		return nil
+	}
+	events := live.DiffStories(before.stories, after, before.at)
+
+	comments, err := store.GetCommentsSince(ctx, before.at, LiveCommentLimit)
+	if err != nil {
+		log.Printf("Failed to fetch new comments for live events: %v", err)
+	}
+	return append(events, live.CommentEvents(comments)...)
+}
+
+// purgeDeletedAccounts permanently removes accounts whose deletion grace period has expired.
+func purgeDeletedAccounts(ctx context.Context, store *storage.Store) {
+	n, err := store.PurgeDeletedAccounts(ctx, storage.AccountDeletionGracePeriod)
+	if err != nil {
+		log.Printf("Failed to purge deleted accounts: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d deleted accounts", n)
+	}
+}
+
+// deleteStaleRateLimitBuckets removes buckets of the Postgres rate limiter
+// that have been idle long enough to have refilled for any configured limit.
+func deleteStaleRateLimitBuckets(ctx context.Context, store *storage.Store) {
+	if _, err := store.DeleteStaleRateLimitBuckets(ctx, 24*time.Hour); err != nil {
+		log.Printf("Failed to delete stale rate limit buckets: %v", err)
+	}
+}
+
+// matchWatchlists records watchlist alerts for items ingested since the
+// watermark and returns the next watermark. The window overlaps the previous
+// one by a minute to absorb clock skew with the database; duplicate alerts
+// are ignored by the notifications unique index.
+func matchWatchlists(ctx context.Context, store *storage.Store, since time.Time) time.Time {
+	next := time.Now()
+	n, err := store.MatchWatchlists(ctx, since.Add(-1*time.Minute))
+	if err != nil {
+		log.Printf("Failed to match watchlists: %v", err)
+		return since
+	}
+	if n > 0 {
+		log.Printf("Recorded %d watchlist notifications", n)
+	}
+	return next
+}
+
 type SummaryJob struct {
 	ID    int
 	URL   string
 	Title string
 }
 
-func startSummaryWorker(ctx context.Context, store *storage.Store, aiClient *ai.GeminiClient, apiKey string, jobs <-chan SummaryJob) {
+func startSummaryWorker(ctx context.Context, store *storage.Store, articleCache *articles.Cache, aiClient *ai.GeminiClient, apiKey string, jobs <-chan SummaryJob) {
 	if apiKey == "" {
 		log.Println("No API key, summary worker disabled.")
 		return
@@ -109,30 +261,37 @@ func startSummaryWorker(ctx context.Context, store *storage.Store, aiClient *ai.
 		case job := <-jobs:
 			// Wait for tick before processing
 			<-limiter.C
-			processSummary(ctx, store, aiClient, apiKey, job)
+			processSummary(ctx, store, articleCache, aiClient, apiKey, job)
 		}
 	}
 }
 
-func processSummary(ctx context.Context, store *storage.Store, aiClient *ai.GeminiClient, apiKey string, job SummaryJob) {
+func processSummary(ctx context.Context, store *storage.Store, articleCache *articles.Cache, aiClient *ai.GeminiClient, apiKey string, job SummaryJob) {
 	log.Printf("Processing summary for story %d: %s", job.ID, job.Title)
 
-	// Use a new context with timeout for the actual work
-	workCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	// Bound the work, and stop it on shutdown
+	workCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	fetchRes, err := content.FetchArticle(job.URL)
+	// Shared with reading mode, so the origin is fetched once per TTL
+	article, err := articleCache.Get(workCtx, int64(job.ID), job.URL)
+	var blocked *articles.BlockedError
+	if errors.As(err, &blocked) {
+		log.Printf("Skipping summary (story %d): %s", job.ID, blocked.Outcome)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to fetch content (story %d): %v", job.ID, err)
 		return
 	}
 
-	if len(fetchRes.Content) < 100 {
+	text := articles.Text(article)
+	if len(text) < 100 {
 		log.Printf("Content too short (story %d)", job.ID)
 		return
 	}
 
-	prompt := fmt.Sprintf("Summarize this Hacker News story/discussion in 3-5 bullet points. Focus on the unique technical details or controversy. Title: %s\n\nText: %s", job.Title, fetchRes.Content)
+	prompt := fmt.Sprintf("Summarize this Hacker News story/discussion in 3-5 bullet points. Focus on the unique technical details or controversy. Title: %s\n\nText: %s", job.Title, text)
 
 	summary, err := aiClient.GenerateSummary(workCtx, apiKey, prompt)
 	if err != nil {
@@ -147,6 +306,200 @@ func processSummary(ctx context.Context, store *storage.Store, aiClient *ai.Gemi
 	}
 }
 
+const (
+	PreviewBatchSize   = 20
+	PreviewInterval    = time.Minute
+	PreviewConcurrency = 4
+)
+
+// startPreviewWorker fetches the pages of ranked and recent stories so that
+// story lists carry Open Graph previews and favicons. The fetch fills the
+// shared article cache, so reading mode and summaries reuse it.
+func startPreviewWorker(ctx context.Context, store *storage.Store, articleCache *articles.Cache) {
+	log.Println("Preview worker started")
+
+	ticker := time.NewTicker(PreviewInterval)
+	defer ticker.Stop()
+
+	for {
+		fetchPendingPreviews(ctx, store, articleCache)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func fetchPendingPreviews(ctx context.Context, store *storage.Store, articleCache *articles.Cache) {
+	stories, err := store.GetStoriesWithoutPreview(ctx, PreviewBatchSize)
+	if err != nil {
+		log.Printf("Failed to fetch stories for previews: %v", err)
+		return
+	}
+
+	jobs := make(chan storage.Story)
+	var wg sync.WaitGroup
+	for i := 0; i < PreviewConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for story := range jobs {
+				workCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+				// Failures are recorded in article_contents and not retried here
+				if _, err := articleCache.Get(workCtx, story.ID, story.URL); err != nil && ctx.Err() == nil {
+					log.Printf("Failed to fetch preview (story %d): %v", story.ID, err)
+				}
+				cancel()
+			}
+		}()
+	}
+
+	for _, story := range stories {
+		select {
+		case jobs <- story:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+const (
+	LinkCheckBatchSize   = 50
+	LinkCheckInterval    = 10 * time.Minute
+	LinkCheckConcurrency = 4
+)
+
+// startLinkChecker periodically checks the URLs of saved stories, so reading
+// mode can fall back to an archived copy once a page is gone.
+func startLinkChecker(ctx context.Context, store *storage.Store, checker *linkcheck.Checker) {
+	log.Println("Link checker started")
+
+	ticker := time.NewTicker(LinkCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		checkDueLinks(ctx, store, checker)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkDueLinks(ctx context.Context, store *storage.Store, checker *linkcheck.Checker) {
+	stories, err := store.GetStoriesDueForLinkCheck(ctx, LinkCheckBatchSize)
+	if err != nil {
+		log.Printf("Failed to fetch stories for link checks: %v", err)
+		return
+	}
+
+	var dead atomic.Int64
+	jobs := make(chan storage.Story)
+	var wg sync.WaitGroup
+	for i := 0; i < LinkCheckConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for story := range jobs {
+				check, err := checker.Check(ctx, story.ID, story.URL)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Failed to check link (story %d): %v", story.ID, err)
+					}
+					continue
+				}
+				if check.Status == storage.LinkStatusDead {
+					dead.Add(1)
+				}
+			}
+		}()
+	}
+
+	for _, story := range stories {
+		select {
+		case jobs <- story:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(stories) > 0 {
+		log.Printf("Checked %d links, %d dead", len(stories), dead.Load())
+	}
+}
+
+const (
+	EmbeddingBatchSize = 50
+	EmbeddingInterval  = 30 * time.Second
+)
+
+// startEmbeddingWorker periodically embeds stories whose embedding is missing,
+// either because they are new or because their summary changed.
+func startEmbeddingWorker(ctx context.Context, store *storage.Store, embedder ai.Embedder) {
+	log.Printf("Embedding worker started (provider: %s)", embedder.Name())
+
+	ticker := time.NewTicker(EmbeddingInterval)
+	defer ticker.Stop()
+
+	for {
+		embedPendingStories(ctx, store, embedder)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func embedPendingStories(ctx context.Context, store *storage.Store, embedder ai.Embedder) {
+	stories, err := store.GetStoriesWithoutEmbedding(ctx, EmbeddingBatchSize)
+	if err != nil {
+		log.Printf("Failed to fetch stories for embedding: %v", err)
+		return
+	}
+
+	embedded := 0
+	for _, story := range stories {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Title is passed separately; the body is the summary when we have one
+		text := story.Title
+		if story.Summary != nil && *story.Summary != "" {
+			text = *story.Summary
+		}
+
+		vec, err := embedder.EmbedDocument(ctx, story.Title, text)
+		if err != nil {
+			log.Printf("Failed to embed story %d: %v", story.ID, err)
+			if ctx.Err() == nil {
+				if err := store.MarkEmbeddingFailed(ctx, story.ID); err != nil {
+					log.Printf("Failed to record embedding attempt (story %d): %v", story.ID, err)
+				}
+			}
+			continue
+		}
+
+		if err := store.UpdateStoryEmbedding(ctx, story.ID, pgvector.NewVector(vec)); err != nil {
+			log.Printf("Failed to save embedding (story %d): %v", story.ID, err)
+			continue
+		}
+		embedded++
+	}
+
+	if embedded > 0 {
+		log.Printf("Embedded %d stories", embedded)
+	}
+}
+
 func runIngestion(ctx context.Context, client *hn.Client, store *storage.Store, aiClient *ai.GeminiClient, summaryQueue chan<- SummaryJob) {
 	// ... (Same fetching logic) ...
 	// Try to get an admin API key for summarization
@@ -14,6 +14,7 @@ import (
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
 	"github.com/rajeshkumarblr/hn_station/internal/api"
 	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/ratelimit"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
@@ -51,13 +52,30 @@ func main() {
 	aiClient := ai.NewGeminiClient()
 	log.Println("Gemini client initialized")
 
+	// Initialize embedder for semantic search (must match the ingest service's provider)
+	embedder, err := ai.NewEmbedder(os.Getenv("EMBEDDING_PROVIDER"), os.Getenv("GEMINI_API_KEY"))
+	if err != nil {
+		log.Printf("Semantic search disabled: %v", err)
+		embedder = nil
+	} else {
+		log.Printf("Embedding provider: %s", embedder.Name())
+	}
+
 	store := storage.New(dbpool)
-	server := api.NewServer(store, authCfg, aiClient)
+	server := api.NewServer(store, authCfg, aiClient, embedder)
+	go server.RunLiveListener(ctx)
+
+	// Share rate limit buckets between replicas when configured
+	if os.Getenv("RATE_LIMIT_BACKEND") == "postgres" {
+		server.SetRateLimiter(ratelimit.NewPostgresLimiter(store))
+		log.Println("Rate limiter backend: postgres")
+	}
 
 	srv := &http.Server{
 		Addr:    ":" + port,
 		Handler: server,
 	}
+	srv.RegisterOnShutdown(server.CloseLive)
 
 	// Handle graceful shutdown
 	go func() {
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -36,7 +37,7 @@ func (c *GeminiClient) GenerateSummary(ctx context.Context, apiKey string, text
 			return "", err
 		}
 
-		prompt := fmt.Sprintf("Summarize this Hacker News story/discussion in 3-5 bullet points. Focus on the unique technical details or controversy. Text: %s", text)
+		prompt := summaryPrompt(text)
 
 		resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 		if err != nil {
@@ -71,42 +72,7 @@ func (c *GeminiClient) GenerateChatResponse(ctx context.Context, apiKey string,
 			return "", err
 		}
 
-		cs := model.StartChat()
-
-		// Set the system instruction or initial context if supported,
-		// or just prepend it to the history/first message.
-		// Gemini Pro often works best if context is in the first message or history.
-
-		// We will construct the history for the session.
-		// We'll inject the context (story content) as a "user" message at the beginning,
-		// followed by a "model" confirmation, to establish context.
-
-		cs.History = []*genai.Content{
-			{
-				Role: "user",
-				Parts: []genai.Part{
-					genai.Text(fmt.Sprintf("Here is the content of the Hacker News story and discussion we will talk about:\n\n%s\n\nPlease answer my future questions based on this context.", contextText)),
-				},
-			},
-			{
-				Role: "model",
-				Parts: []genai.Part{
-					genai.Text("Understood. I have read the story and discussion. I am ready to answer your questions about it."),
-				},
-			},
-		}
-
-		// Append actual user history
-		for _, msg := range history {
-			role := "user"
-			if msg.Role == "model" || msg.Role == "assistant" {
-				role = "model"
-			}
-			cs.History = append(cs.History, &genai.Content{
-				Role:  role,
-				Parts: []genai.Part{genai.Text(msg.Content)},
-			})
-		}
+		cs := startChat(model, contextText, history)
 
 		resp, err := cs.SendMessage(ctx, genai.Text(newMessage))
 		if err != nil {
@@ -118,6 +84,46 @@ func (c *GeminiClient) GenerateChatResponse(ctx context.Context, apiKey string,
 	})
 }
 
+func summaryPrompt(text string) string {
+	return fmt.Sprintf("Summarize this Hacker News story/discussion in 3-5 bullet points. Focus on the unique technical details or controversy. Text: %s", text)
+}
+
+// startChat creates a chat session primed with the story context and the
+// prior conversation.
+func startChat(model *genai.GenerativeModel, contextText string, history []ChatMessage) *genai.ChatSession {
+	cs := model.StartChat()
+
+	// We inject the context (story content) as a "user" message at the beginning,
+	// followed by a "model" confirmation, to establish context.
+	cs.History = []*genai.Content{
+		{
+			Role: "user",
+			Parts: []genai.Part{
+				genai.Text(fmt.Sprintf("Here is the content of the Hacker News story and discussion we will talk about:\n\n%s\n\nPlease answer my future questions based on this context.", contextText)),
+			},
+		},
+		{
+			Role: "model",
+			Parts: []genai.Part{
+				genai.Text("Understood. I have read the story and discussion. I am ready to answer your questions about it."),
+			},
+		},
+	}
+
+	// Append actual user history
+	for _, msg := range history {
+		role := "user"
+		if msg.Role == "model" || msg.Role == "assistant" {
+			role = "model"
+		}
+		cs.History = append(cs.History, &genai.Content{
+			Role:  role,
+			Parts: []genai.Part{genai.Text(msg.Content)},
+		})
+	}
+	return cs
+}
+
 func (c *GeminiClient) getBestModel(ctx context.Context, client *genai.Client) (*genai.GenerativeModel, error) {
 	// Skip dynamic discovery to save quota/latency for now.
 	// Gemini Flash is generally available and best for this use case.
@@ -159,6 +165,11 @@ func (c *GeminiClient) generateWithRetry(ctx context.Context, operation func() (
 		}
 
 		lastErr = err
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return result, permanent.err
+		}
+
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "429") || strings.Contains(errMsg, "Quota") || strings.Contains(errMsg, "quota") {
 			log.Printf("GeminiClient: Quota exceeded (attempt %d/%d), retrying in %v...", retries+1, maxRetries, backoff)
@@ -0,0 +1,199 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// EmbeddingDimensions matches the stories.embedding vector(768) column.
+const EmbeddingDimensions = 768
+
+// Embedder turns text into a fixed-size vector for semantic search.
+type Embedder interface {
+	// EmbedDocument embeds stored content (story title, summary, article text).
+	EmbedDocument(ctx context.Context, title, text string) ([]float32, error)
+	// EmbedQuery embeds a user search query.
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+	// Name identifies the provider, e.g. for logging.
+	Name() string
+	// MinSimilarity is the cosine similarity below which results from this
+	// provider should be treated as unrelated. It is model-dependent.
+	MinSimilarity() float64
+}
+
+// NewEmbedder returns the embedder for the given provider name.
+// Supported providers are "hash" (default, offline) and "gemini".
+func NewEmbedder(provider, apiKey string) (Embedder, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", "hash":
+		return NewHashEmbedder(), nil
+	case "gemini":
+		if apiKey == "" {
+			return nil, fmt.Errorf("gemini embedder requires an API key")
+		}
+		return NewGeminiEmbedder(apiKey), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", provider)
+	}
+}
+
+// ─── Gemini ───
+
+// GeminiEmbedder uses Google's text-embedding-004 model (768 dimensions).
+type GeminiEmbedder struct {
+	apiKey string
+	model  string
+}
+
+// NewGeminiEmbedder creates an embedder backed by the Gemini embedding API.
+func NewGeminiEmbedder(apiKey string) *GeminiEmbedder {
+	return &GeminiEmbedder{apiKey: apiKey, model: "text-embedding-004"}
+}
+
+func (e *GeminiEmbedder) Name() string {
+	return "gemini"
+}
+
+func (e *GeminiEmbedder) MinSimilarity() float64 {
+	return 0.5
+}
+
+func (e *GeminiEmbedder) EmbedDocument(ctx context.Context, title, text string) ([]float32, error) {
+	return e.embed(ctx, genai.TaskTypeRetrievalDocument, title, text)
+}
+
+func (e *GeminiEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return e.embed(ctx, genai.TaskTypeRetrievalQuery, "", query)
+}
+
+func (e *GeminiEmbedder) embed(ctx context.Context, taskType genai.TaskType, title, text string) ([]float32, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(e.apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gemini client: %w", err)
+	}
+	defer client.Close()
+
+	model := client.EmbeddingModel(e.model)
+	model.TaskType = taskType
+
+	var resp *genai.EmbedContentResponse
+	if title != "" {
+		resp, err = model.EmbedContentWithTitle(ctx, title, genai.Text(text))
+	} else {
+		resp, err = model.EmbedContent(ctx, genai.Text(text))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if resp.Embedding == nil || len(resp.Embedding.Values) != EmbeddingDimensions {
+		return nil, fmt.Errorf("unexpected embedding size from model")
+	}
+	return resp.Embedding.Values, nil
+}
+
+// ─── Hash (offline) ───
+
+// HashEmbedder is a deterministic, dependency-free embedder based on feature
+// hashing of word unigrams and bigrams. It is much weaker than a learned model,
+// but stories sharing vocabulary end up close together, which is enough for
+// local development and tests.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a hash embedder producing EmbeddingDimensions vectors.
+func NewHashEmbedder() *HashEmbedder {
+	return &HashEmbedder{dims: EmbeddingDimensions}
+}
+
+func (e *HashEmbedder) Name() string {
+	return "hash"
+}
+
+// MinSimilarity is low because hashed bag-of-words vectors only overlap on
+// shared vocabulary, and short queries share few words with long documents.
+func (e *HashEmbedder) MinSimilarity() float64 {
+	return 0.1
+}
+
+func (e *HashEmbedder) EmbedDocument(ctx context.Context, title, text string) ([]float32, error) {
+	return e.embed(title + " " + text), nil
+}
+
+func (e *HashEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return e.embed(query), nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dims)
+	tokens := tokenize(text)
+
+	for i, tok := range tokens {
+		e.add(vec, tok, 1.0)
+		if i > 0 {
+			e.add(vec, tokens[i-1]+" "+tok, 0.5)
+		}
+	}
+
+	// L2-normalize so cosine distance behaves sensibly
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		// pgvector rejects zero vectors for cosine distance; use a fixed unit vector
+		vec[0] = 1
+		return vec
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+	return vec
+}
+
+func (e *HashEmbedder) add(vec []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	idx := int(sum % uint64(e.dims))
+	// Use an independent bit for the sign to reduce collision bias
+	if (sum>>63)&1 == 1 {
+		weight = -weight
+	}
+	vec[idx] += weight
+}
+
+// tokenize lowercases text and splits it into alphanumeric words, dropping
+// very short tokens and common stop words.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) < 2 || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "are": true, "was": true, "but": true, "not": true, "you": true,
+	"your": true, "have": true, "has": true, "its": true, "into": true, "how": true,
+	"why": true, "what": true, "of": true, "to": true, "in": true, "on": true,
+	"is": true, "it": true, "an": true, "as": true, "at": true, "by": true,
+	"be": true, "or": true, "we": true, "hn": true,
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	e := NewHashEmbedder()
+	ctx := context.Background()
+
+	a, err := e.EmbedDocument(ctx, "Postgres 17 released", "New features in PostgreSQL")
+	assert.NoError(t, err)
+	b, err := e.EmbedDocument(ctx, "Postgres 17 released", "New features in PostgreSQL")
+	assert.NoError(t, err)
+
+	assert.Len(t, a, EmbeddingDimensions)
+	assert.Equal(t, a, b)
+	assert.InDelta(t, 1.0, cosine(a, a), 1e-6)
+}
+
+func TestHashEmbedder_SharedVocabularyIsCloser(t *testing.T) {
+	e := NewHashEmbedder()
+	ctx := context.Background()
+
+	query, _ := e.EmbedQuery(ctx, "rust compiler")
+	related, _ := e.EmbedDocument(ctx, "Speeding up the Rust compiler", "Incremental compilation tricks in rustc")
+	unrelated, _ := e.EmbedDocument(ctx, "Sourdough baking at altitude", "Hydration and proofing times")
+
+	assert.Greater(t, cosine(query, related), e.MinSimilarity())
+	assert.Greater(t, cosine(query, related), cosine(query, unrelated))
+}
+
+func TestHashEmbedder_EmptyTextIsNonZero(t *testing.T) {
+	vec, err := NewHashEmbedder().EmbedQuery(context.Background(), "")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0, cosine(vec, vec), 1e-6)
+}
+
+func TestNewEmbedder(t *testing.T) {
+	e, err := NewEmbedder("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", e.Name())
+
+	_, err = NewEmbedder("gemini", "")
+	assert.Error(t, err)
+
+	_, err = NewEmbedder("word2vec", "")
+	assert.Error(t, err)
+}
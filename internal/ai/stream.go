@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// ChunkFunc receives each piece of generated text as it arrives. Returning an
+// error (e.g. because the client disconnected) aborts the stream.
+type ChunkFunc func(text string) error
+
+// permanentError marks a failure that generateWithRetry must not retry, such
+// as one that happens after part of a stream was already delivered.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// StreamSummary is the streaming variant of GenerateSummary. It calls onChunk
+// for each piece of text and returns the complete summary.
+func (c *GeminiClient) StreamSummary(ctx context.Context, apiKey string, text string, onChunk ChunkFunc) (string, error) {
+	log.Printf("GeminiClient: Starting streaming summarization. Input text length: %d", len(text))
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to create gemini client: %w", err)
+	}
+	defer client.Close()
+
+	return c.generateWithRetry(ctx, func() (string, error) {
+		model, err := c.getBestModel(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		return collectStream(model.GenerateContentStream(ctx, genai.Text(summaryPrompt(text))), onChunk)
+	})
+}
+
+// StreamChatResponse is the streaming variant of GenerateChatResponse.
+func (c *GeminiClient) StreamChatResponse(ctx context.Context, apiKey string, contextText string, history []ChatMessage, newMessage string, onChunk ChunkFunc) (string, error) {
+	log.Printf("GeminiClient: Starting streaming chat. History length: %d", len(history))
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to create gemini client: %w", err)
+	}
+	defer client.Close()
+
+	return c.generateWithRetry(ctx, func() (string, error) {
+		model, err := c.getBestModel(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		cs := startChat(model, contextText, history)
+		return collectStream(cs.SendMessageStream(ctx, genai.Text(newMessage)), onChunk)
+	})
+}
+
+// responseIterator is the subset of *genai.GenerateContentResponseIterator
+// used by collectStream.
+type responseIterator interface {
+	Next() (*genai.GenerateContentResponse, error)
+}
+
+// collectStream relays text from the iterator to onChunk and returns the
+// concatenated text. Errors after the first chunk are permanent, since a
+// retry would repeat text the caller has already sent on.
+func collectStream(iter responseIterator, onChunk ChunkFunc) (string, error) {
+	var sb strings.Builder
+	for {
+		resp, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			log.Printf("GeminiClient: Stream failed: %v", err)
+			err = fmt.Errorf("stream failed: %w", err)
+			if sb.Len() > 0 {
+				return sb.String(), &permanentError{err}
+			}
+			return "", err
+		}
+
+		chunk := responseText(resp)
+		if chunk == "" {
+			continue
+		}
+		sb.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return sb.String(), &permanentError{err}
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("empty text response from model")
+	}
+	return sb.String(), nil
+}
+
+// responseText returns the text parts of the first candidate, or "".
+func responseText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if txt, ok := part.(genai.Text); ok {
+			sb.WriteString(string(txt))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/iterator"
+)
+
+type fakeIterator struct {
+	chunks []string
+	err    error // returned after the chunks instead of iterator.Done
+}
+
+func (f *fakeIterator) Next() (*genai.GenerateContentResponse, error) {
+	if len(f.chunks) == 0 {
+		if f.err != nil {
+			return nil, f.err
+		}
+		return nil, iterator.Done
+	}
+	text := f.chunks[0]
+	f.chunks = f.chunks[1:]
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text(text)}}}},
+	}, nil
+}
+
+func TestCollectStream(t *testing.T) {
+	var got []string
+	text, err := collectStream(&fakeIterator{chunks: []string{"Hello", "", ", world"}}, func(s string) error {
+		got = append(got, s)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, world", text)
+	assert.Equal(t, []string{"Hello", ", world"}, got)
+}
+
+func TestCollectStream_ErrorBeforeFirstChunkIsRetryable(t *testing.T) {
+	_, err := collectStream(&fakeIterator{err: errors.New("429 quota")}, func(string) error { return nil })
+
+	var permanent *permanentError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &permanent))
+}
+
+func TestCollectStream_ErrorAfterChunkIsPermanent(t *testing.T) {
+	text, err := collectStream(&fakeIterator{chunks: []string{"partial"}, err: errors.New("429 quota")}, func(string) error { return nil })
+
+	var permanent *permanentError
+	assert.True(t, errors.As(err, &permanent))
+	assert.Equal(t, "partial", text)
+}
+
+func TestCollectStream_ClientGone(t *testing.T) {
+	gone := errors.New("client disconnected")
+	_, err := collectStream(&fakeIterator{chunks: []string{"a", "b"}}, func(string) error { return gone })
+
+	assert.ErrorIs(t, err, gone)
+}
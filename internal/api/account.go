@@ -0,0 +1,191 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// requireActiveAccount rejects API requests from sessions of accounts that
+// are pending deletion (or gone), so other devices lose access as soon as
+// deletion is requested. Signing in again restores the account, as does
+// POST /api/me/restore, which stays open to those sessions.
+func (s *Server) requireActiveAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := ""
+		if strings.HasPrefix(r.URL.Path, "/api/") && r.URL.Path != "/api/me/restore" {
+			userID = s.auth.GetUserIDFromRequest(r)
+		}
+		if userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		active, err := s.store.IsAccountActive(r.Context(), userID)
+		if err != nil {
+			log.Printf("Failed to check account status: %v", err)
+			http.Error(w, "Failed to load account", http.StatusInternalServerError)
+			return
+		}
+		if !active {
+			auth.ClearSessionCookie(w, isSecureRequest(r))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "account pending deletion; sign in again to restore it"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleDeleteMe schedules the account for deletion. The body must repeat the
+// account email: {"confirm_email": "..."}. Until the grace period ends the
+// account can be restored by signing in again or with POST /api/me/restore;
+// meanwhile its sessions cannot use the API (see requireActiveAccount).
+func (s *Server) handleDeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		ConfirmEmail string `json:"confirm_email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.store.GetAuthUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(body.ConfirmEmail), user.Email) {
+		http.Error(w, "confirm_email must match the account email", http.StatusBadRequest)
+		return
+	}
+
+	requestedAt, err := s.store.RequestAccountDeletion(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to schedule account deletion: %v", err)
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	auth.ClearSessionCookie(w, isSecureRequest(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deletion_requested_at": requestedAt,
+		"deletion_scheduled_at": requestedAt.Add(storage.AccountDeletionGracePeriod),
+	})
+}
+
+// handleRestoreMe cancels a pending account deletion.
+func (s *Server) handleRestoreMe(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := s.store.CancelAccountDeletion(r.Context(), userID); err != nil {
+		log.Printf("Failed to cancel account deletion: %v", err)
+		http.Error(w, "Failed to restore account", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGetMyData returns everything stored about the user as a JSON download.
+// The Gemini API key is never included; only whether one is set.
+func (s *Server) handleGetMyData(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := s.store.GetAuthUser(ctx, userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	interactions, err := s.store.GetInteractionsForExport(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to export interactions: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+	chats, err := s.store.GetAllChatMessages(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to export chat history: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+	filters, err := s.store.GetUserFilters(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to export filters: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+	watchlist, err := s.store.GetWatchlist(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to export watchlist: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	notifications, err := s.store.GetAllNotifications(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to export notifications: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	archive := map[string]interface{}{
+		"exported_at": time.Now().UTC(),
+		"profile": map[string]interface{}{
+			"id":                    user.ID,
+			"google_id":             user.GoogleID,
+			"email":                 user.Email,
+			"name":                  user.Name,
+			"avatar_url":            user.AvatarURL,
+			"is_admin":              user.IsAdmin,
+			"created_at":            user.CreatedAt,
+			"deletion_requested_at": user.DeletionRequestedAt,
+		},
+		"settings": map[string]interface{}{
+			"gemini_api_key_set": user.GeminiAPIKey != "",
+			"filters":            emptyIfNil(filters),
+			"watchlist":          emptyIfNil(watchlist),
+		},
+		"interactions":  emptyIfNil(interactions),
+		"chat_messages": emptyIfNil(chats),
+		"notifications": emptyIfNil(notifications),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="hn-station-data.json"`)
+	json.NewEncoder(w).Encode(archive)
+}
+
+// emptyIfNil makes nil slices encode as [] rather than null.
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
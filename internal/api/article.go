@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/articles"
 )
 
 func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request) {
@@ -57,12 +59,10 @@ func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request)
 	var errFetch error
 
 	if story.URL != "" {
-		content, _, _, err := s.fetchArticleContent(story.URL)
+		article, err := s.articles.Get(r.Context(), story.ID, story.URL)
 		if err == nil {
-			// For summarization, we'd prefer text content, but Go-Readability's Content is HTML.
-			// Ideally we should strip tags for Gemini to save tokens, but Gemini handles HTML fine.
-			// Let's use the content we got.
-			textContent = content
+			// Plain text keeps the prompt free of markup tokens
+			textContent = articles.Text(article)
 		} else {
 			errFetch = err
 		}
@@ -73,18 +73,19 @@ func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Don't spend a Gemini call summarizing a paywall or bot check
+	var blocked *articles.BlockedError
+	if errors.As(errFetch, &blocked) {
+		writeBlocked(w, blocked.Outcome)
+		return
+	}
 	if errFetch != nil || len(textContent) < 100 {
 		http.Error(w, "Failed to fetch article content. It might be behind a paywall or inaccessible.", http.StatusBadGateway)
 		return
 	}
 
 	// 3. Summarize with Gemini
-	// Initialize truncated content
-	finalContent := textContent
-	// If it's raw HTML, we might want to strip script/style tags if possible, but Gemini handles it okay.
-	// For now, raw HTML is better than nothing.
-
-	prompt := fmt.Sprintf("Title: %s\nURL: %s\n\nArticle Content:\n%s", story.Title, story.URL, finalContent)
+	prompt := fmt.Sprintf("Title: %s\nURL: %s\n\nArticle Content:\n%s", story.Title, story.URL, textContent)
 	summary, err := s.aiClient.GenerateSummary(r.Context(), user.GeminiAPIKey, prompt)
 	if err != nil {
 		log.Printf("Summarization failed: %v", err)
@@ -96,6 +97,7 @@ func (s *Server) handleSummarizeArticle(w http.ResponseWriter, r *http.Request)
 	if err := s.store.UpdateStorySummary(r.Context(), id, summary); err != nil {
 		log.Printf("Failed to update story summary cache: %v", err)
 	}
+	s.invalidateStory(id)
 
 	// 5. Save to Chat History
 	if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Article Summary of \"%s\":**\n\n%s", story.Title, summary)); err != nil {
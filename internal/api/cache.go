@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/cache"
+)
+
+const (
+	cacheNamespaceStories = "stories" // /api/stories
+	cacheNamespaceStory   = "story"   // /api/stories/{id}
+
+	responseCacheTTL     = 2 * time.Minute // Fallback if an ingest_complete event is missed
+	responseCacheEntries = 2000
+)
+
+// cacheKey identifies an anonymous /api/stories response.
+func (p storyListParams) cacheKey() string {
+	return strings.Join([]string{
+		strconv.Itoa(p.Limit), strconv.Itoa(p.Offset), p.Sort, strconv.Itoa(p.MinPoints),
+		strings.Join(p.Topics, "\x1f"),
+	}, "|")
+}
+
+// encodeJSON produces the same bytes as json.NewEncoder(w).Encode(v).
+func encodeJSON(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// etagMatches implements the weak comparison If-None-Match requires.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONWithETag writes a JSON body with a strong ETag, or a 304 if the
+// client already has it. Clients must revalidate, so data is never stale.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, body []byte, etag string, private bool) {
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Vary", "Cookie")
+	if private {
+		h.Set("Cache-Control", "private, no-cache")
+	} else {
+		h.Set("Cache-Control", "public, no-cache")
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// serveCached answers an anonymous request from the response cache.
+// Returns false on a miss.
+func (s *Server) serveCached(w http.ResponseWriter, r *http.Request, namespace, key string) bool {
+	entry, ok := s.cache.Get(namespace, key)
+	if !ok {
+		return false
+	}
+	w.Header().Set("X-Cache", "HIT")
+	writeJSONWithETag(w, r, entry.Body, entry.ETag, false)
+	return true
+}
+
+// respondJSON encodes v and writes it with an ETag. Anonymous responses
+// (private == false) are stored in the cache under the version read before
+// the data was loaded.
+func (s *Server) respondJSON(w http.ResponseWriter, r *http.Request, v interface{}, private bool, namespace, key string, version int64) {
+	body, err := encodeJSON(v)
+	if err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	if private {
+		writeJSONWithETag(w, r, body, cache.ETag(body), true)
+		return
+	}
+
+	entry := s.cache.Set(namespace, key, body, version)
+	w.Header().Set("X-Cache", "MISS")
+	writeJSONWithETag(w, r, entry.Body, entry.ETag, false)
+}
+
+// invalidateStory drops the cached responses showing a story after a write
+// on this replica: its details and every listing, which could include it.
+func (s *Server) invalidateStory(id int) {
+	s.cache.Invalidate(cacheNamespaceStory, strconv.Itoa(id))
+	s.cache.InvalidateNamespace(cacheNamespaceStories)
+}
+
+// handleGetCacheStats reports response cache hit/miss counters (admin only).
+func (s *Server) handleGetCacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.cache.Stats())
+}
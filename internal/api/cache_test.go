@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtagMatches(t *testing.T) {
+	etag := `"abc"`
+	assert.False(t, etagMatches("", etag))
+	assert.True(t, etagMatches(`"abc"`, etag))
+	assert.True(t, etagMatches(`W/"abc"`, etag))
+	assert.True(t, etagMatches(`"xyz", "abc"`, etag))
+	assert.True(t, etagMatches("*", etag))
+	assert.False(t, etagMatches(`"xyz"`, etag))
+}
+
+func TestRespondJSON_CachesAnonymousResponses(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	version := server.cache.Version()
+
+	req := httptest.NewRequest("GET", "/api/stories/1", nil)
+	rr := httptest.NewRecorder()
+	server.respondJSON(rr, req, map[string]int{"id": 1}, false, cacheNamespaceStory, "1", version)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+	assert.Equal(t, "public, no-cache", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "{\"id\":1}\n", rr.Body.String())
+	etag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// Revalidation with the ETag is answered from the cache without a body
+	req = httptest.NewRequest("GET", "/api/stories/1", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	assert.True(t, server.serveCached(rr, req, cacheNamespaceStory, "1"))
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+	assert.Empty(t, rr.Body.String())
+
+	// A new ingest run drops the entry
+	server.cache.SetVersion(version + 1)
+	assert.False(t, server.serveCached(httptest.NewRecorder(), req, cacheNamespaceStory, "1"))
+}
+
+func TestRespondJSON_PrivateResponsesAreNotCached(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/stories/1", nil)
+	rr := httptest.NewRecorder()
+	server.respondJSON(rr, req, map[string]int{"id": 1}, true, cacheNamespaceStory, "1", server.cache.Version())
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "private, no-cache", rr.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+	assert.False(t, server.serveCached(httptest.NewRecorder(), req, cacheNamespaceStory, "1"))
+}
+
+func TestInvalidateStory_DropsDetailsAndListings(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	version := server.cache.Version()
+	req := httptest.NewRequest("GET", "/api/stories", nil)
+	server.respondJSON(httptest.NewRecorder(), req, map[string]int{"id": 1}, false, cacheNamespaceStory, "1", version)
+	server.respondJSON(httptest.NewRecorder(), req, []int{1}, false, cacheNamespaceStories, "30|0|rank|0|", version)
+
+	server.invalidateStory(1)
+	assert.False(t, server.serveCached(httptest.NewRecorder(), req, cacheNamespaceStory, "1"))
+	assert.False(t, server.serveCached(httptest.NewRecorder(), req, cacheNamespaceStories, "30|0|rank|0|"))
+}
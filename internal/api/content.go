@@ -2,22 +2,25 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/articles"
 	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
 )
 
-var httpClient = &http.Client{Timeout: 10 * time.Second}
-
-// handleGetReadme fetches a GitHub repo's README.md and returns raw Markdown.
+// handleGetReadme resolves the README of a GitHub repository, or of a
+// directory for /tree/ and /blob/ URLs, through the GitHub API and returns
+// it as sanitized HTML: /api/content/readme?url=...&format=html|md.
 func (s *Server) handleGetReadme(w http.ResponseWriter, r *http.Request) {
 	rawURL := r.URL.Query().Get("url")
 	if rawURL == "" {
@@ -25,59 +28,103 @@ func (s *Server) handleGetReadme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	owner, repo, err := parseGitHubURL(rawURL)
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	if format != "html" && format != "md" {
+		http.Error(w, "format must be html or md", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := parseGitHubURL(rawURL)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Try main first, then master
-	for _, branch := range []string{"main", "master"} {
-		readmeURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/README.md", owner, repo, branch)
-		resp, err := httpClient.Get(readmeURL)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
+	readme, err := s.github.Readme(r.Context(), s.fetcher, loc.owner, loc.repo, loc.ref, loc.dir)
+	if err != nil {
+		log.Printf("Failed to resolve README for %s: %v", rawURL, err)
+		http.Error(w, "README not found", http.StatusNotFound)
+		return
+	}
 
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				http.Error(w, "Failed to read README", http.StatusInternalServerError)
-				return
-			}
-			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-			w.Header().Set("Cache-Control", "public, max-age=300")
-			w.Write(body)
+	body := readme.Source
+	if format == "html" {
+		rendered, err := readme.HTML()
+		if err != nil {
+			log.Printf("Failed to render README for %s: %v", rawURL, err)
+			http.Error(w, "Failed to render README", http.StatusInternalServerError)
 			return
 		}
+		body = content.Sanitize(rendered, readme.LinkBase)
 	}
 
-	http.Error(w, "README not found", http.StatusNotFound)
+	response := struct {
+		Content string `json:"content"`
+		Format  string `json:"format"`
+		Repo    string `json:"repo"`
+		Ref     string `json:"ref"`
+		Path    string `json:"path"`
+		URL     string `json:"url"`
+	}{
+		Content: body,
+		Format:  format,
+		Repo:    readme.Repo,
+		Ref:     readme.Ref,
+		Path:    readme.Path,
+		URL:     readme.URL,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(response)
 }
 
-// parseGitHubURL extracts owner and repo from a GitHub URL.
-func parseGitHubURL(rawURL string) (string, string, error) {
+// gitHubLocation is a repository directory named by a GitHub URL.
+type gitHubLocation struct {
+	owner, repo string
+	ref         string // Empty for the default branch
+	dir         string // Empty for the repository root
+}
+
+// parseGitHubURL extracts the repository, ref and directory from a GitHub
+// URL: /owner/repo, /owner/repo/tree/{ref}/{dir} or /owner/repo/blob/{ref}/{file}.
+// Refs containing slashes are not supported.
+func parseGitHubURL(rawURL string) (gitHubLocation, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", "", fmt.Errorf("invalid URL")
+		return gitHubLocation{}, fmt.Errorf("invalid URL")
 	}
 
 	host := strings.ToLower(u.Hostname())
 	if host != "github.com" && host != "www.github.com" {
-		return "", "", fmt.Errorf("not a GitHub URL")
+		return gitHubLocation{}, fmt.Errorf("not a GitHub URL")
 	}
 
-	// Path: /owner/repo or /owner/repo/...
 	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("cannot parse owner/repo from URL")
+	if len(parts) < 2 || parts[0] == "" {
+		return gitHubLocation{}, fmt.Errorf("cannot parse owner/repo from URL")
 	}
 
-	return parts[0], parts[1], nil
+	loc := gitHubLocation{owner: parts[0], repo: strings.TrimSuffix(parts[1], ".git")}
+	if len(parts) >= 4 {
+		switch parts[2] {
+		case "tree":
+			loc.ref, loc.dir = parts[3], strings.Join(parts[4:], "/")
+		case "blob":
+			loc.ref, loc.dir = parts[3], path.Dir(strings.Join(parts[4:], "/"))
+			if loc.dir == "." {
+				loc.dir = ""
+			}
+		}
+	}
+	return loc, nil
 }
 
-// handleGetArticleContent fetches the main content of a story's URL.
+// handleGetArticleContent fetches the main content of a story's URL:
+// /api/stories/{id}/content?format=html|md|text (default html).
 func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
@@ -86,6 +133,15 @@ func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+	if format != "html" && format != "md" && format != "text" {
+		http.Error(w, "format must be html, md or text", http.StatusBadRequest)
+		return
+	}
+
 	story, err := s.store.GetStory(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Story not found", http.StatusNotFound)
@@ -97,35 +153,81 @@ func (s *Server) handleGetArticleContent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	content, title, canIframe, err := s.fetchArticleContent(story.URL)
+	// Known-dead links go straight to the archive
+	var linkStatus *string
+	check, err := s.store.GetLinkCheck(r.Context(), story.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Failed to get link check for story %d: %v", story.ID, err)
+	}
+	if check != nil && check.URL == story.URL {
+		linkStatus = &check.Status
+	}
+	dead := linkStatus != nil && *linkStatus == storage.LinkStatusDead
+
+	article, source, err := s.articles.Resolve(r.Context(), story.ID, story.URL, dead)
+	var blocked *articles.BlockedError
+	if errors.As(err, &blocked) {
+		writeBlocked(w, blocked.Outcome)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to fetch article content for %s: %v", story.URL, err)
 		http.Error(w, "Failed to fetch content", http.StatusBadGateway)
 		return
 	}
 
+	text := articles.Text(article)
+	var body string
+	switch format {
+	case "md":
+		body = content.Markdown(article.ContentHTML, story.URL)
+	case "text":
+		body = text
+	default:
+		body = content.Sanitize(article.ContentHTML, story.URL)
+	}
+	words := content.WordCount(text)
+
 	// Return simple JSON struct
 	response := struct {
-		Content   string `json:"content"`
-		Title     string `json:"title"`
-		URL       string `json:"url"`
-		CanIframe bool   `json:"can_iframe"`
+		Content            string    `json:"content"`
+		Format             string    `json:"format"`
+		Title              string    `json:"title"`
+		URL                string    `json:"url"`
+		CanIframe          bool      `json:"can_iframe"`
+		WordCount          int       `json:"word_count"`
+		ReadingTimeMinutes int       `json:"reading_time_minutes"`
+		FetchedAt          time.Time `json:"fetched_at"`
+		Stale              bool      `json:"stale"`  // Last refresh failed; content is from an earlier fetch
+		Source             string    `json:"source"` // origin, archive or cache
+		ArchiveURL         *string   `json:"archive_url,omitempty"`
+		LinkStatus         *string   `json:"link_status,omitempty"` // Latest link check: ok, error or dead
+		Outcome            string    `json:"outcome"`               // Latest fetch: article, error, or the wall in front of it
 	}{
-		Content:   content,
-		Title:     title,
-		URL:       story.URL,
-		CanIframe: canIframe,
+		Content:            body,
+		Format:             format,
+		Title:              article.Title,
+		URL:                story.URL,
+		CanIframe:          article.CanIframe,
+		WordCount:          words,
+		ReadingTimeMinutes: content.ReadingMinutes(words),
+		FetchedAt:          article.FetchedAt,
+		Stale:              article.Status != storage.ArticleStatusOK || source == articles.SourceCache,
+		Source:             source,
+		ArchiveURL:         article.ArchiveURL,
+		LinkStatus:         linkStatus,
+		Outcome:            article.Outcome,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// fetchArticleContent uses the shared internal/content package to fetch and parse the article.
-func (s *Server) fetchArticleContent(urlStr string) (string, string, bool, error) {
-	result, err := content.FetchArticle(urlStr)
-	if err != nil {
-		return "", "", false, err
-	}
-	return result.Content, result.Title, result.CanIframe, nil
+// writeBlocked reports that the origin served a paywall, login wall, bot
+// check or consent page instead of the article. It is a 422 rather than a
+// 502: the site answered, just not with anything worth reading.
+func writeBlocked(w http.ResponseWriter, o content.Outcome) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]string{"error": o.Describe(), "outcome": string(o)})
 }
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitHubURL(t *testing.T) {
+	cases := map[string]gitHubLocation{
+		"https://github.com/user/repo":                          {owner: "user", repo: "repo"},
+		"https://www.github.com/user/repo.git":                  {owner: "user", repo: "repo"},
+		"https://github.com/user/repo/tree/dev/docs/guide":      {owner: "user", repo: "repo", ref: "dev", dir: "docs/guide"},
+		"https://github.com/user/repo/blob/v1.0/docs/README.md": {owner: "user", repo: "repo", ref: "v1.0", dir: "docs"},
+		"https://github.com/user/repo/blob/main/README.org":     {owner: "user", repo: "repo", ref: "main"},
+	}
+	for raw, want := range cases {
+		got, err := parseGitHubURL(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	for _, raw := range []string{"https://gitlab.com/user/repo", "https://github.com/user"} {
+		_, err := parseGitHubURL(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+// stubGitHub points the server's fetcher and GitHub client at handler.
+func stubGitHub(t *testing.T, server *Server, handler http.Handler) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	server.fetcher = content.NewFetcher(&http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, ts.Listener.Addr().String())
+		},
+	})
+	server.github = &content.GitHubExtractor{APIBase: "http://github.test"}
+}
+
+func TestGetReadme_RendersSanitizedHTML(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	stubGitHub(t, server, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.RequestURI() {
+		case "/repos/user/repo":
+			w.Write([]byte(`{"full_name":"user/repo","default_branch":"trunk","html_url":"https://github.com/user/repo"}`))
+		case "/repos/user/repo/readme?ref=trunk":
+			source := "# Repo\n\n![logo](img/logo.png) [Guide](docs/guide.md)\n\n<script>alert(1)</script>\n"
+			json.NewEncoder(w).Encode(map[string]string{
+				"path":     "readme.md",
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte(source)),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	req := httptest.NewRequest("GET", "/api/content/readme?url=https://github.com/user/repo", nil)
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp struct {
+		Content, Format, Repo, Ref, Path, URL string
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "html", resp.Format)
+	assert.Equal(t, "trunk", resp.Ref)
+	assert.Equal(t, "readme.md", resp.Path)
+	assert.Equal(t, "https://github.com/user/repo/blob/trunk/readme.md", resp.URL)
+	assert.Contains(t, resp.Content, `src="https://raw.githubusercontent.com/user/repo/trunk/img/logo.png"`)
+	assert.Contains(t, resp.Content, `href="https://github.com/user/repo/blob/trunk/docs/guide.md"`)
+	assert.NotContains(t, resp.Content, "<script")
+}
+
+func TestGetReadme_LocalStandInFromEnv(t *testing.T) {
+	// A stand-in on loopback and a random port, configured only through the
+	// environment, is reachable despite the SSRF checks on user URLs
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.RequestURI() {
+		case "/repos/user/repo":
+			w.Write([]byte(`{"full_name":"user/repo","default_branch":"main","html_url":"https://github.com/user/repo"}`))
+		case "/repos/user/repo/readme?ref=main":
+			json.NewEncoder(w).Encode(map[string]string{
+				"path":     "README.md",
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte("# Stand-in\n")),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("GITHUB_API_URL", ts.URL)
+	server := NewServer(nil, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/content/readme?url=https://github.com/user/repo&format=md", nil)
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "# Stand-in")
+}
+
+func TestGetReadme_NotFound(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	stubGitHub(t, server, http.NotFoundHandler())
+
+	req := httptest.NewRequest("GET", "/api/content/readme?url=https://github.com/user/missing", nil)
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
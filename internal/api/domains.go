@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/rajeshkumarblr/hn_station/internal/urlnorm"
+)
+
+// parseWindow reads the ?days= stats window (default 30, max 3650).
+func parseWindow(r *http.Request) time.Time {
+	days := 30
+	if val, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && val > 0 && val <= 3650 {
+		days = val
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// handleGetDomainStories lists a site's stories along with its stats over the window.
+func (s *Server) handleGetDomainStories(w http.ResponseWriter, r *http.Request) {
+	domain := urlnorm.Domain(chi.URLParam(r, "domain"))
+	if domain == "" {
+		http.Error(w, "Invalid domain", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	offset := 0
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 200 {
+		limit = val
+	}
+	if val, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && val >= 0 {
+		offset = val
+	}
+
+	userID := s.auth.GetUserIDFromRequest(r)
+	stories, err := s.store.GetStoriesByDomain(r.Context(), domain, limit, offset, r.URL.Query().Get("sort"), userID)
+	if err != nil {
+		log.Printf("Failed to fetch stories for domain %s: %v", domain, err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+
+	stats, err := s.store.GetDomainStats(r.Context(), domain, parseWindow(r))
+	if err != nil {
+		log.Printf("Failed to fetch stats for domain %s: %v", domain, err)
+		http.Error(w, "Failed to fetch domain stats", http.StatusInternalServerError)
+		return
+	}
+
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	response := struct {
+		Domain  string               `json:"domain"`
+		Stats   *storage.DomainStats `json:"stats"`
+		Stories []storage.Story      `json:"stories"`
+	}{
+		Domain:  domain,
+		Stats:   stats,
+		Stories: stories,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleGetTopDomains ranks sites by story count over the ?days= window.
+func (s *Server) handleGetTopDomains(w http.ResponseWriter, r *http.Request) {
+	limit := 25
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 200 {
+		limit = val
+	}
+
+	domains, err := s.store.GetTopDomains(r.Context(), parseWindow(r), limit)
+	if err != nil {
+		log.Printf("Failed to fetch top domains: %v", err)
+		http.Error(w, "Failed to fetch domains", http.StatusInternalServerError)
+		return
+	}
+
+	if domains == nil {
+		domains = []storage.DomainStats{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domains)
+}
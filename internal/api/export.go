@@ -0,0 +1,398 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/bookmarks"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	maxImportBytes   = 5 * 1024 * 1024
+	maxImportItems   = 2000
+	maxImportFetches = 100 // stories fetched from the HN API per import
+
+	importFetchConcurrency = 8
+)
+
+// Bookmark exports put stories that are not saved in these folders. Import
+// maps them back to read and hidden stories when the bookmark came from an
+// export, which it knows by the HN item ID attribute.
+const (
+	readHistoryFolder = "Read History"
+	hiddenFolder      = "Hidden"
+)
+
+func hnItemURL(id int64) string {
+	return fmt.Sprintf("https://news.ycombinator.com/item?id=%d", id)
+}
+
+// handleExport downloads the user's interactions: /api/me/export?format=json|csv|html
+// JSON also includes chat history; CSV and bookmarks HTML cover stories only.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "html" {
+		http.Error(w, "format must be json, csv or html", http.StatusBadRequest)
+		return
+	}
+
+	items, err := s.store.GetInteractionsForExport(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to export interactions: %v", err)
+		http.Error(w, "Failed to export", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []storage.InteractionExport{}
+	}
+
+	filename := "hn-station-export-" + time.Now().UTC().Format("2006-01-02") + "." + format
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	switch format {
+	case "json":
+		chats, err := s.store.GetAllChatMessages(r.Context(), userID)
+		if err != nil {
+			log.Printf("Failed to export chat history: %v", err)
+			http.Error(w, "Failed to export", http.StatusInternalServerError)
+			return
+		}
+		if chats == nil {
+			chats = []storage.ChatMessage{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"exported_at":   time.Now().UTC(),
+			"interactions":  items,
+			"chat_messages": chats,
+		})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = writeInteractionsCSV(w, items)
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = bookmarks.Write(w, "HN Station", interactionsToBookmarks(items))
+	}
+	// The status is already sent, so a failed write can only be logged
+	if err != nil {
+		log.Printf("Failed to write %s export: %v", format, err)
+	}
+}
+
+func writeInteractionsCSV(w io.Writer, items []storage.InteractionExport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"story_id", "title", "url", "hn_url", "saved", "read", "hidden", "folder", "tags", "note", "updated_at"})
+	for _, it := range items {
+		cw.Write([]string{
+			strconv.FormatInt(it.StoryID, 10),
+			it.Title,
+			it.URL,
+			hnItemURL(it.StoryID),
+			strconv.FormatBool(it.IsSaved),
+			strconv.FormatBool(it.IsRead),
+			strconv.FormatBool(it.IsHidden),
+			derefString(it.Folder),
+			strings.Join(it.Tags, ";"),
+			derefString(it.Note),
+			it.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// interactionsToBookmarks keeps saved stories in their own folders and puts
+// read-only and hidden stories in the readHistoryFolder and hiddenFolder.
+// The HN item ID goes in its own attribute so the note is only the user's.
+func interactionsToBookmarks(items []storage.InteractionExport) []bookmarks.Bookmark {
+	var out []bookmarks.Bookmark
+	for _, it := range items {
+		link := it.URL
+		if link == "" {
+			link = hnItemURL(it.StoryID)
+		}
+
+		folder := derefString(it.Folder)
+		switch {
+		case it.IsSaved:
+		case it.IsHidden:
+			folder = hiddenFolder
+		default:
+			folder = readHistoryFolder
+		}
+
+		out = append(out, bookmarks.Bookmark{
+			URL:      link,
+			Title:    it.Title,
+			Folder:   folder,
+			Tags:     it.Tags,
+			Note:     derefString(it.Note),
+			AddedAt:  it.UpdatedAt,
+			HNItemID: int(it.StoryID),
+		})
+	}
+	return out
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ─── Import ───
+
+var importTokenSplit = regexp.MustCompile(`[\s,]+`)
+
+// parseImport accepts a Netscape bookmarks file or a plain list of HN item
+// IDs / URLs separated by whitespace or commas.
+func parseImport(body []byte) ([]bookmarks.Bookmark, error) {
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return bookmarks.Parse(bytes.NewReader(trimmed))
+	}
+
+	var out []bookmarks.Bookmark
+	for _, tok := range importTokenSplit.Split(string(trimmed), -1) {
+		if tok != "" {
+			out = append(out, bookmarks.Bookmark{URL: tok})
+		}
+	}
+	return out, nil
+}
+
+// bookmarkItemID returns the HN item a bookmark refers to: the one an export
+// recorded, or one named by its URL.
+func bookmarkItemID(b bookmarks.Bookmark) (int, bool) {
+	if b.HNItemID > 0 {
+		return b.HNItemID, true
+	}
+	return hnItemID(b.URL)
+}
+
+// hnItemID extracts an HN item ID from a bare number or a news.ycombinator.com item URL.
+func hnItemID(ref string) (int, bool) {
+	if id, err := strconv.Atoi(ref); err == nil && id > 0 {
+		return id, true
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || !strings.EqualFold(u.Hostname(), "news.ycombinator.com") || u.Path != "/item" {
+		return 0, false
+	}
+	id, err := strconv.Atoi(u.Query().Get("id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// resolveImport maps bookmarks to stored story IDs, 0 for those that cannot
+// be resolved. Stored stories are looked up in two queries; HN items not in
+// the database yet are fetched concurrently, up to maxImportFetches. Returns
+// the IDs and the number of items fetched.
+func (s *Server) resolveImport(ctx context.Context, items []bookmarks.Bookmark) ([]int, int, error) {
+	var itemIDs []int
+	var articleURLs []string
+	for _, b := range items {
+		if id, ok := bookmarkItemID(b); ok {
+			itemIDs = append(itemIDs, id)
+		} else {
+			articleURLs = append(articleURLs, b.URL)
+		}
+	}
+
+	existing, err := s.store.ExistingStoryIDs(ctx, itemIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	// An article URL matches earlier submissions we already know about
+	byURL, err := s.store.StoryIDsByURL(ctx, articleURLs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var missing []int
+	for _, id := range itemIDs {
+		if !existing[id] && !slices.Contains(missing, id) && len(missing) < maxImportFetches {
+			missing = append(missing, id)
+		}
+	}
+	for id := range s.fetchImportedStories(ctx, missing) {
+		existing[id] = true
+	}
+
+	ids := make([]int, len(items))
+	for i, b := range items {
+		if id, ok := bookmarkItemID(b); ok {
+			if existing[id] {
+				ids[i] = id
+			}
+		} else {
+			ids[i] = byURL[b.URL]
+		}
+	}
+	return ids, len(missing), nil
+}
+
+// fetchImportedStories fetches HN items and stores those that are live
+// stories, returning the IDs stored.
+func (s *Server) fetchImportedStories(ctx context.Context, ids []int) map[int]bool {
+	stored := make(map[int]bool, len(ids))
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < importFetchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if s.fetchImportedStory(ctx, id) {
+					mu.Lock()
+					stored[id] = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return stored
+}
+
+func (s *Server) fetchImportedStory(ctx context.Context, id int) bool {
+	item, err := s.hnClient.GetItem(ctx, id)
+	if err != nil || item.Type != "story" || item.Deleted || item.Dead {
+		return false
+	}
+
+	story := storage.Story{
+		ID:          int64(item.ID),
+		Title:       item.Title,
+		URL:         item.URL,
+		Score:       item.Score,
+		By:          item.By,
+		Descendants: item.Descendants,
+		PostedAt:    time.Unix(item.Time, 0),
+	}
+	if err := s.store.UpsertStory(ctx, story); err != nil {
+		log.Printf("Failed to store imported story %d: %v", id, err)
+		return false
+	}
+	return true
+}
+
+// importedInteractions pairs bookmarks with their resolved story IDs.
+// Bookmarks are saved unless importing reading history (markRead) or they
+// come from an export's read history or hidden folders. Tags and folders
+// belong to saved stories, so the others carry only notes; writing them
+// would save the story.
+func importedInteractions(items []bookmarks.Bookmark, ids []int, markRead bool) ([]storage.ImportedInteraction, []string) {
+	var out []storage.ImportedInteraction
+	unresolved := []string{}
+	for i, b := range items {
+		if ids[i] == 0 {
+			unresolved = append(unresolved, b.URL)
+			continue
+		}
+		exported := b.HNItemID > 0
+		it := storage.ImportedInteraction{StoryID: ids[i], Note: b.Note}
+		switch {
+		case exported && b.Folder == hiddenFolder:
+			it.State = storage.ImportHidden
+		case markRead || exported && b.Folder == readHistoryFolder:
+			it.State = storage.ImportRead
+		default:
+			it.State = storage.ImportSaved
+			it.Tags = b.Tags
+			it.Folder = b.Folder
+		}
+		out = append(out, it)
+	}
+	return out, unresolved
+}
+
+// handleImport marks the referenced stories as saved (or read with ?mark=read),
+// carrying over bookmark folders, tags and notes.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	markRead := r.URL.Query().Get("mark") == "read"
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		http.Error(w, "Import too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	items, err := parseImport(body)
+	if err != nil {
+		http.Error(w, "Failed to parse import", http.StatusBadRequest)
+		return
+	}
+	if len(items) > maxImportItems {
+		http.Error(w, fmt.Sprintf("Import is limited to %d items", maxImportItems), http.StatusBadRequest)
+		return
+	}
+
+	ids, fetched, err := s.resolveImport(r.Context(), items)
+	if err != nil {
+		log.Printf("Failed to resolve import: %v", err)
+		http.Error(w, "Failed to import", http.StatusInternalServerError)
+		return
+	}
+
+	interactions, unresolved := importedInteractions(items, ids, markRead)
+	partial, err := s.store.ImportInteractions(r.Context(), userID, interactions)
+	if err != nil {
+		log.Printf("Failed to import: %v", err)
+		http.Error(w, "Failed to import", http.StatusInternalServerError)
+		return
+	}
+	if partial == nil {
+		partial = []int{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imported":   len(interactions),
+		"fetched":    fetched,
+		"unresolved": unresolved,
+		"partial":    partial, // Imported without some tags, note or folder
+	})
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/feeds"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	defaultFeedItems = 30
+	maxFeedItems     = 100
+)
+
+// registerFeedRoutes adds one route per feed format, e.g. /feeds/stories.rss.
+func (s *Server) registerFeedRoutes() {
+	r := s.router.With(s.rateLimit(rateLimitFeeds))
+	for ext := range feeds.Formats {
+		r.Get("/feeds/stories."+ext, s.handleStoriesFeed(ext))
+		r.Get("/feeds/u/{token}/saved."+ext, s.handleSavedFeed(ext))
+		r.Get("/feeds/u/{token}/watchlist."+ext, s.handleWatchlistFeed(ext))
+	}
+}
+
+// baseURL is the site's public origin for absolute links in feeds, from
+// PUBLIC_BASE_URL. Without it (local development) the request's own Host is
+// used, which clients control, so production deployments must set it.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.publicBaseURL != "" {
+		return s.publicBaseURL
+	}
+	scheme := "http"
+	if isSecureRequest(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func writeFeed(w http.ResponseWriter, ext string, feed *feeds.Feed) {
+	w.Header().Set("Content-Type", feeds.ContentTypes[ext])
+	if err := feeds.Formats[ext](w, feed); err != nil {
+		log.Printf("Failed to write %s feed: %v", ext, err)
+	}
+}
+
+// summaryHTML renders a plain-text AI summary as escaped HTML paragraphs.
+func summaryHTML(summary string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.TrimSpace(summary), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// storyFeedItem converts a story to a feed item. The summary, when cached,
+// becomes the item summary and leads the HTML content.
+func storyFeedItem(st storage.Story) feeds.Item {
+	comments := hnItemURL(st.ID)
+	link := comments
+	if st.URL != "" {
+		link = st.URL
+	}
+
+	item := feeds.Item{
+		ID:          comments,
+		Title:       st.Title,
+		Link:        link,
+		CommentsURL: comments,
+		Author:      st.By,
+		Published:   st.PostedAt,
+	}
+	if st.Domain != nil {
+		item.Categories = []string{*st.Domain}
+	}
+
+	var content strings.Builder
+	if st.Summary != nil && *st.Summary != "" {
+		item.Summary = *st.Summary
+		content.WriteString(summaryHTML(*st.Summary))
+	}
+	fmt.Fprintf(&content, `<p>%d points by %s | <a href="%s">%d comments</a></p>`,
+		st.Score, html.EscapeString(st.By), html.EscapeString(comments), st.Descendants)
+	item.ContentHTML = content.String()
+	return item
+}
+
+func feedLimit(limit int) int {
+	if limit > maxFeedItems {
+		return maxFeedItems
+	}
+	return limit
+}
+
+// handleStoriesFeed serves /feeds/stories.{rss,atom,json} with the same
+// sort, list, topic and min_points parameters as /api/stories.
+// Feeds are always anonymous so they are identical for every reader.
+func (s *Server) handleStoriesFeed(ext string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := parseStoryListParams(r, defaultFeedItems)
+
+		stories, err := s.store.GetStories(r.Context(), feedLimit(params.Limit), params.Offset, params.Sort, params.Topics, params.MinPoints, "", false)
+		if err != nil {
+			log.Printf("Failed to fetch stories for feed: %v", err)
+			http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+			return
+		}
+
+		title := "HN Station"
+		switch params.Sort {
+		case "latest":
+			title += ": New"
+		case "votes":
+			title += ": Best"
+		case "show":
+			title += ": Show HN"
+		}
+		if len(params.Topics) > 0 {
+			title += " (" + strings.Join(params.Topics, ", ") + ")"
+		}
+
+		feed := &feeds.Feed{
+			Title:       title,
+			Link:        s.baseURL(r) + "/",
+			FeedURL:     s.baseURL(r) + r.URL.RequestURI(),
+			Description: "Hacker News stories from HN Station",
+			Updated:     time.Now(),
+		}
+		for _, st := range stories {
+			feed.Items = append(feed.Items, storyFeedItem(st))
+		}
+		writeFeed(w, ext, feed)
+	}
+}
+
+// feedUser resolves the {token} URL parameter of a private feed, writing a
+// 404 for unknown tokens so valid ones cannot be probed.
+func (s *Server) feedUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, err := s.store.GetUserIDByFeedToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to resolve feed token: %v", err)
+		}
+		http.NotFound(w, r)
+		return "", false
+	}
+	return userID, true
+}
+
+// handleSavedFeed serves a user's saved stories at /feeds/u/{token}/saved.*
+func (s *Server) handleSavedFeed(ext string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := s.feedUser(w, r)
+		if !ok {
+			return
+		}
+
+		filter := storage.SavedStoriesFilter{
+			Tag:    r.URL.Query().Get("tag"),
+			Folder: r.URL.Query().Get("folder"),
+		}
+		stories, err := s.store.GetSavedStories(r.Context(), userID, filter, defaultFeedItems, 0)
+		if err != nil {
+			log.Printf("Failed to fetch saved stories for feed: %v", err)
+			http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+			return
+		}
+
+		feed := &feeds.Feed{
+			Title:       "HN Station: Saved Stories",
+			Link:        s.baseURL(r) + "/",
+			FeedURL:     s.baseURL(r) + r.URL.RequestURI(),
+			Description: "Your saved Hacker News stories",
+			Updated:     time.Now(),
+		}
+		for _, st := range stories {
+			item := storyFeedItem(st)
+			item.Categories = append(item.Categories, st.Tags...)
+			feed.Items = append(feed.Items, item)
+		}
+		writeFeed(w, ext, feed)
+	}
+}
+
+// handleWatchlistFeed serves watchlist hits at /feeds/u/{token}/watchlist.*
+func (s *Server) handleWatchlistFeed(ext string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := s.feedUser(w, r)
+		if !ok {
+			return
+		}
+
+		notifications, err := s.store.GetNotifications(r.Context(), userID, false, defaultFeedItems, 0)
+		if err != nil {
+			log.Printf("Failed to fetch notifications for feed: %v", err)
+			http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+			return
+		}
+
+		feed := &feeds.Feed{
+			Title:       "HN Station: Watchlist",
+			Link:        s.baseURL(r) + "/",
+			FeedURL:     s.baseURL(r) + r.URL.RequestURI(),
+			Description: "Stories and comments matching your watchlist",
+			Updated:     time.Now(),
+		}
+		for _, n := range notifications {
+			feed.Items = append(feed.Items, notificationFeedItem(n))
+		}
+		writeFeed(w, ext, feed)
+	}
+}
+
+func notificationFeedItem(n storage.Notification) feeds.Item {
+	comments := hnItemURL(n.StoryID)
+	item := feeds.Item{
+		ID:          fmt.Sprintf("%s#watch-%d", comments, n.ID),
+		Title:       n.StoryTitle,
+		Link:        n.StoryURL,
+		CommentsURL: comments,
+		Author:      n.StoryBy,
+		Published:   n.CreatedAt,
+		Categories:  []string{n.WatchKind + ":" + n.WatchPattern},
+	}
+	if item.Link == "" {
+		item.Link = comments
+	}
+
+	if n.CommentID != nil {
+		// Comment hit: link straight to the comment
+		item.Link = hnItemURL(*n.CommentID)
+		item.Title = "Comment on: " + n.StoryTitle
+		if n.CommentBy != nil {
+			item.Author = *n.CommentBy
+		}
+		if n.CommentText != nil {
+			// HN comment text is already HTML
+			item.ContentHTML = content.Sanitize(*n.CommentText, content.HNBaseURL)
+		}
+		return item
+	}
+
+	if n.StorySummary != nil && *n.StorySummary != "" {
+		item.Summary = *n.StorySummary
+		item.ContentHTML = summaryHTML(*n.StorySummary)
+	}
+	return item
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// handleGetFeedURLs returns the user's private feed URLs, creating the token
+// on first use.
+func (s *Server) handleGetFeedURLs(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	candidate, err := newFeedToken()
+	if err != nil {
+		http.Error(w, "Failed to create feed token", http.StatusInternalServerError)
+		return
+	}
+	token, err := s.store.GetOrCreateFeedToken(r.Context(), userID, candidate)
+	if err != nil {
+		log.Printf("Failed to get feed token: %v", err)
+		http.Error(w, "Failed to create feed token", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeFeedURLs(w, r, token)
+}
+
+// handleRotateFeedToken replaces the feed token, revoking old feed URLs.
+func (s *Server) handleRotateFeedToken(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		http.Error(w, "Failed to create feed token", http.StatusInternalServerError)
+		return
+	}
+	if err := s.store.SetFeedToken(r.Context(), userID, token); err != nil {
+		log.Printf("Failed to rotate feed token: %v", err)
+		http.Error(w, "Failed to rotate feed token", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeFeedURLs(w, r, token)
+}
+
+func (s *Server) writeFeedURLs(w http.ResponseWriter, r *http.Request, token string) {
+	base := s.baseURL(r) + "/feeds/u/" + token
+	urls := map[string]map[string]string{"saved": {}, "watchlist": {}}
+	for ext := range feeds.Formats {
+		urls["saved"][ext] = base + "/saved." + ext
+		urls["watchlist"][ext] = base + "/watchlist." + ext
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token": token,
+		"feeds": urls,
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStoryListParams(t *testing.T) {
+	r := &http.Request{URL: &url.URL{RawQuery: "list=newest&topic=rust&topic=+&min_points=50&limit=10"}}
+	p := parseStoryListParams(r, 30)
+
+	assert.Equal(t, "latest", p.Sort)
+	assert.Equal(t, []string{"rust"}, p.Topics)
+	assert.Equal(t, 50, p.MinPoints)
+	assert.Equal(t, 10, p.Limit)
+
+	p = parseStoryListParams(&http.Request{URL: &url.URL{RawQuery: "sort=bogus"}}, 30)
+	assert.Equal(t, "default", p.Sort)
+	assert.Equal(t, 30, p.Limit)
+
+	// Limits are clamped before they become part of the cache key
+	p = parseStoryListParams(&http.Request{URL: &url.URL{RawQuery: "limit=100000"}}, 30)
+	assert.Equal(t, maxStoryListLimit, p.Limit)
+	assert.Equal(t, p.cacheKey(), parseStoryListParams(&http.Request{URL: &url.URL{RawQuery: "limit=5000"}}, 30).cacheKey())
+}
+
+func TestStoryFeedItem(t *testing.T) {
+	summary := "- fast\n- safe <really>"
+	domain := "example.com"
+	item := storyFeedItem(storage.Story{
+		ID: 42, Title: "Rust 2.0", URL: "https://example.com/rust", By: "dtolnay",
+		Score: 120, Descendants: 8, PostedAt: time.Unix(0, 0), Summary: &summary, Domain: &domain,
+	})
+
+	assert.Equal(t, "https://news.ycombinator.com/item?id=42", item.ID)
+	assert.Equal(t, "https://example.com/rust", item.Link)
+	assert.Equal(t, summary, item.Summary)
+	assert.Contains(t, item.ContentHTML, "<p>- fast<br>- safe &lt;really&gt;</p>")
+	assert.Contains(t, item.ContentHTML, "8 comments")
+	assert.Equal(t, []string{"example.com"}, item.Categories)
+
+	// Text posts link to the discussion
+	item = storyFeedItem(storage.Story{ID: 7, Title: "Ask HN"})
+	assert.Equal(t, "https://news.ycombinator.com/item?id=7", item.Link)
+	assert.Empty(t, item.Summary)
+}
+
+func TestBaseURL_IgnoresHostHeaderWhenConfigured(t *testing.T) {
+	r := &http.Request{Host: "evil.example", Header: http.Header{}, URL: &url.URL{Path: "/feeds/stories.rss"}}
+
+	assert.Equal(t, "http://evil.example", (&Server{}).baseURL(r))
+
+	t.Setenv("PUBLIC_BASE_URL", "https://hnstation.dev/")
+	server := NewServer(nil, nil, nil, nil)
+	assert.Equal(t, "https://hnstation.dev", server.baseURL(r))
+}
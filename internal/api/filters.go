@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+type filterRequest struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+}
+
+// writeFilterError maps storage errors from mute-rule operations to HTTP responses.
+func writeFilterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrInvalidFilter):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Filter not found", http.StatusNotFound)
+	default:
+		log.Printf("Filter operation failed: %v", err)
+		http.Error(w, "Failed to update filters", http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleGetFilters(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	filters, err := s.store.GetUserFilters(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch filters: %v", err)
+		http.Error(w, "Failed to fetch filters", http.StatusInternalServerError)
+		return
+	}
+
+	if filters == nil {
+		filters = []storage.UserFilter{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filters)
+}
+
+func (s *Server) handleCreateFilter(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body filterRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := s.store.CreateUserFilter(r.Context(), userID, body.Kind, body.Pattern)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(filter)
+}
+
+func (s *Server) handleUpdateFilter(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "filterID"))
+	if err != nil {
+		http.Error(w, "Invalid filter ID", http.StatusBadRequest)
+		return
+	}
+
+	var body filterRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := s.store.UpdateUserFilter(r.Context(), userID, id, body.Kind, body.Pattern)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filter)
+}
+
+func (s *Server) handleDeleteFilter(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "filterID"))
+	if err != nil {
+		http.Error(w, "Invalid filter ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.DeleteUserFilter(r.Context(), userID, id); err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/live"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	livePingInterval      = 25 * time.Second
+	liveReconnectDelay    = 5 * time.Second
+	maxLiveStorySubscribe = 20
+)
+
+// RunLiveListener relays events published by the ingest service over Postgres
+// NOTIFY to /api/live clients and invalidates the response cache after each
+// ingest run, reconnecting until ctx is cancelled.
+func (s *Server) RunLiveListener(ctx context.Context) {
+	for {
+		// Catch up on runs missed while disconnected
+		if version, err := s.store.GetIngestVersion(ctx); err == nil {
+			s.cache.SetVersion(version)
+		}
+
+		err := s.store.Listen(ctx, storage.LiveChannel, func(payload string) {
+			events, err := live.DecodePayload(payload)
+			if err != nil {
+				log.Printf("Invalid live event payload: %v", err)
+				return
+			}
+			for _, ev := range events {
+				if ev.Type == live.EventIngestComplete {
+					s.cache.SetVersion(ev.Version)
+				}
+			}
+			s.live.Publish(events)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Live event listener stopped: %v; reconnecting in %v", err, liveReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(liveReconnectDelay):
+		}
+	}
+}
+
+// CloseLive ends open /api/live streams so a graceful shutdown does not wait on them.
+func (s *Server) CloseLive() {
+	s.live.Close()
+}
+
+// handleLive streams front-page events (new_story, story_update) as SSE.
+// new_comment events are sent for stories listed in ?story= (up to 20).
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	var storyIDs []int64
+	for _, raw := range r.URL.Query()["story"] {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid story ID", http.StatusBadRequest)
+			return
+		}
+		storyIDs = append(storyIDs, id)
+	}
+	if len(storyIDs) > maxLiveStorySubscribe {
+		http.Error(w, "Too many story subscriptions", http.StatusBadRequest)
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sub := s.live.Subscribe(storyIDs)
+	defer s.live.Unsubscribe(sub)
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.live.Done():
+			return
+		case ev := <-sub.C:
+			if err := sse.Event(ev.Type, ev); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := sse.Ping(); err != nil {
+				return
+			}
+		}
+	}
+}
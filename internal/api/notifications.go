@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// ─── Watchlist Handlers ───
+
+func (s *Server) handleGetWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := s.store.GetWatchlist(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch watchlist: %v", err)
+		http.Error(w, "Failed to fetch watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	if entries == nil {
+		entries = []storage.WatchlistEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (s *Server) handleCreateWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Kind    string `json:"kind"`
+		Pattern string `json:"pattern"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := s.store.CreateWatchlistEntry(r.Context(), userID, body.Kind, body.Pattern)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (s *Server) handleDeleteWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "watchID"))
+	if err != nil {
+		http.Error(w, "Invalid watchlist ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.DeleteWatchlistEntry(r.Context(), userID, id); err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Notification Handlers ───
+
+// handleGetNotifications returns the user's alert feed: /api/notifications?unread=true
+func (s *Server) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 200 {
+		limit = val
+	}
+	if val, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && val >= 0 {
+		offset = val
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := s.store.GetNotifications(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch notifications: %v", err)
+		http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := s.store.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to count notifications: %v", err)
+		http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+
+	if notifications == nil {
+		notifications = []storage.Notification{}
+	}
+	sanitizeNotifications(notifications)
+
+	response := struct {
+		UnreadCount   int                    `json:"unread_count"`
+		Notifications []storage.Notification `json:"notifications"`
+	}{
+		UnreadCount:   unread,
+		Notifications: notifications,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// sanitizeNotifications cleans the (truncated) comment HTML of alerts.
+func sanitizeNotifications(notifications []storage.Notification) {
+	for i := range notifications {
+		if text := notifications[i].CommentText; text != nil {
+			sanitized := content.Sanitize(*text, content.HNBaseURL)
+			notifications[i].CommentText = &sanitized
+		}
+	}
+}
+
+// handleMarkNotifications sets read state. An empty ids list applies to all.
+func (s *Server) handleMarkNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		IDs  []int64 `json:"ids"`
+		Read *bool   `json:"read"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	isRead := true
+	if body.Read != nil {
+		isRead = *body.Read
+	}
+
+	if err := s.store.MarkNotificationsRead(r.Context(), userID, body.IDs, isRead); err != nil {
+		log.Printf("Failed to update notifications: %v", err)
+		http.Error(w, "Failed to update notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ratelimit"
+)
+
+// Rate limit route groups.
+const (
+	rateLimitAPI     = "api"
+	rateLimitContent = "content" // Fetches third-party pages on the caller's behalf
+	rateLimitAI      = "ai"
+	rateLimitData    = "data" // Import, export and personal-data archive
+	rateLimitAuth    = "auth"
+	rateLimitFeeds   = "feeds"
+)
+
+// rateLimitGroups holds the default limit for each group. Each can be
+// overridden with RATE_LIMIT_<GROUP> (e.g. RATE_LIMIT_CONTENT=30/m:10) or
+// disabled with RATE_LIMIT_<GROUP>=off.
+var rateLimitGroups = map[string]string{
+	rateLimitAPI:     "300/m:100",
+	rateLimitContent: "20/m:10",
+	rateLimitAI:      "10/m:5",
+	rateLimitData:    "10/h:5",
+	rateLimitAuth:    "30/m:10",
+	rateLimitFeeds:   "60/m:20",
+}
+
+// loadRateLimits resolves the limit for every group. Disabled groups are
+// left out of the returned map.
+func loadRateLimits(getenv func(string) string) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit, len(rateLimitGroups))
+	for group, spec := range rateLimitGroups {
+		if override := strings.TrimSpace(getenv("RATE_LIMIT_" + strings.ToUpper(group))); override != "" {
+			if override == "off" {
+				continue
+			}
+			if _, err := ratelimit.ParseLimit(override); err != nil {
+				log.Printf("Ignoring RATE_LIMIT_%s: %v", strings.ToUpper(group), err)
+			} else {
+				spec = override
+			}
+		}
+
+		limit, err := ratelimit.ParseLimit(spec)
+		if err != nil {
+			panic(err) // Defaults are constants
+		}
+		limits[group] = limit
+	}
+	return limits
+}
+
+// SetRateLimiter replaces the default in-memory limiter, e.g. with the
+// Postgres backend when running several replicas.
+func (s *Server) SetRateLimiter(l ratelimit.Limiter) {
+	s.limiter = l
+}
+
+// parseTrustedProxies reads TRUSTED_PROXIES, a comma-separated list of IPs
+// and CIDRs of the reverse proxies in front of the server. Invalid entries
+// are logged and skipped.
+func parseTrustedProxies(spec string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Ignoring TRUSTED_PROXIES entry %q: %v", entry, err)
+			continue
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
+
+func ipIn(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// realIP sets RemoteAddr to the client address reported by X-Forwarded-For
+// or X-Real-IP, but only for requests from a trusted proxy; anyone else could
+// send those headers to pose as many clients. X-Forwarded-For is read from
+// the right, skipping trusted proxies, since clients can prepend entries.
+func realIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer := net.ParseIP(remoteHost(r)); peer != nil && ipIn(peer, trusted) {
+				if client := forwardedClient(r.Header, trusted); client != "" {
+					r.RemoteAddr = client
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forwardedClient(h http.Header, trusted []*net.IPNet) string {
+	hops := strings.Split(strings.Join(h.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !ipIn(ip, trusted) {
+			return ip.String()
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(h.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// rateLimitKey identifies the caller: the user when logged in, else the client IP.
+func (s *Server) rateLimitKey(r *http.Request) string {
+	if userID := s.auth.GetUserIDFromRequest(r); userID != "" {
+		return "user:" + userID
+	}
+	// realIP has already applied forwarding headers from trusted proxies
+	return "ip:" + remoteHost(r)
+}
+
+// remoteHost is the IP part of RemoteAddr.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimit enforces the group's token bucket and sets RateLimit-* headers.
+// If the backend fails, requests are let through rather than failing the API.
+func (s *Server) rateLimit(group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, ok := s.limits[group]
+			if !ok || s.limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := s.limiter.Allow(r.Context(), group+":"+s.rateLimitKey(r), limit)
+			if err != nil {
+				log.Printf("Rate limiter error (group %s): %v", group, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds())))
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+
+			if !res.Allowed {
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRateLimits(t *testing.T) {
+	env := map[string]string{
+		"RATE_LIMIT_CONTENT": "5/m:2",
+		"RATE_LIMIT_FEEDS":   "off",
+		"RATE_LIMIT_AI":      "nonsense",
+	}
+	limits := loadRateLimits(func(k string) string { return env[k] })
+
+	assert.Equal(t, ratelimit.Limit{Requests: 5, Period: time.Minute, Burst: 2}, limits[rateLimitContent])
+	assert.NotContains(t, limits, rateLimitFeeds)
+	assert.Equal(t, ratelimit.Limit{Requests: 10, Period: time.Minute, Burst: 5}, limits[rateLimitAI])
+}
+
+func TestRateLimit_Returns429(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	server.limits[rateLimitAPI] = ratelimit.Limit{Requests: 1, Period: time.Minute, Burst: 1}
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/me/restore", nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("203.0.113.5:1234")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	rr = do("203.0.113.5:5678")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	// A different client IP has its own bucket
+	rr = do("198.51.100.7:1234")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestRateLimit_IgnoresForwardedHeadersFromUntrustedPeers(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+	server.limits[rateLimitAPI] = ratelimit.Limit{Requests: 1, Period: time.Minute, Burst: 1}
+
+	do := func(forwardedFor string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/me/restore", nil)
+		req.RemoteAddr = "203.0.113.5:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("198.51.100.1").Code)
+	// A fresh spoofed address does not get a fresh bucket
+	assert.Equal(t, http.StatusTooManyRequests, do("198.51.100.2").Code)
+}
+
+func TestRateLimit_TrustedProxyForwardsClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+	server := NewServer(nil, nil, nil, nil)
+	server.limits[rateLimitAPI] = ratelimit.Limit{Requests: 1, Period: time.Minute, Burst: 1}
+
+	do := func(forwardedFor string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/me/restore", nil)
+		req.RemoteAddr = "10.0.0.2:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("203.0.113.5").Code)
+	// Entries the client prepends are ignored; the proxies' own are skipped
+	assert.Equal(t, http.StatusTooManyRequests, do("198.51.100.9, 203.0.113.5, 192.0.2.1").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("198.51.100.9").Code)
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets := parseTrustedProxies("10.0.0.0/8, 192.0.2.1,::1, bogus")
+	assert.Len(t, nets, 3)
+	assert.Equal(t, "192.0.2.1/32", nets[1].String())
+	assert.Equal(t, "::1/128", nets[2].String())
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	pgvector "github.com/pgvector/pgvector-go"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// handleGetRelatedStories returns semantically similar stories and earlier
+// submissions of the same URL ("past discussions").
+func (s *Server) handleGetRelatedStories(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	limit := 10
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 && val <= 50 {
+		limit = val
+	}
+
+	story, err := s.store.GetStory(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
+
+	past, err := s.store.GetPastDiscussions(r.Context(), id, limit)
+	if err != nil {
+		log.Printf("Failed to fetch past discussions for %d: %v", id, err)
+		http.Error(w, "Failed to fetch past discussions", http.StatusInternalServerError)
+		return
+	}
+
+	var related []storage.Story
+	if s.embedder != nil {
+		embedding, err := s.store.GetStoryEmbedding(r.Context(), id)
+		if err != nil {
+			log.Printf("Failed to fetch embedding for %d: %v", id, err)
+		}
+
+		// Not embedded yet (new story): embed the title on the fly
+		if embedding == nil {
+			vec, err := s.embedder.EmbedDocument(r.Context(), story.Title, story.Title)
+			if err != nil {
+				log.Printf("Failed to embed story %d: %v", id, err)
+			} else {
+				v := pgvector.NewVector(vec)
+				embedding = &v
+			}
+		}
+
+		if embedding != nil {
+			related, err = s.store.GetRelatedStories(r.Context(), id, *embedding, limit, s.embedder.MinSimilarity(), s.auth.GetUserIDFromRequest(r))
+			if err != nil {
+				log.Printf("Failed to fetch related stories for %d: %v", id, err)
+				http.Error(w, "Failed to fetch related stories", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if related == nil {
+		related = []storage.Story{}
+	}
+	if past == nil {
+		past = []storage.Story{}
+	}
+
+	response := struct {
+		Related         []storage.Story `json:"related"`
+		PastDiscussions []storage.Story `json:"past_discussions"`
+	}{
+		Related:         related,
+		PastDiscussions: past,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleGetStoriesByURL lists every stored submission of a URL, for
+// duplicate-submission checks: /api/stories/by-url?url=...
+func (s *Server) handleGetStoriesByURL(w http.ResponseWriter, r *http.Request) {
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		http.Error(w, "url parameter required", http.StatusBadRequest)
+		return
+	}
+
+	stories, err := s.store.GetStoriesByURL(r.Context(), rawURL, 50)
+	if err != nil {
+		log.Printf("Failed to look up stories by URL: %v", err)
+		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
+		return
+	}
+
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stories)
+}
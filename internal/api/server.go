@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -14,8 +16,15 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	pgvector "github.com/pgvector/pgvector-go"
 	"github.com/rajeshkumarblr/hn_station/internal/ai"
+	"github.com/rajeshkumarblr/hn_station/internal/articles"
 	"github.com/rajeshkumarblr/hn_station/internal/auth"
+	"github.com/rajeshkumarblr/hn_station/internal/cache"
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/hn"
+	"github.com/rajeshkumarblr/hn_station/internal/live"
+	"github.com/rajeshkumarblr/hn_station/internal/ratelimit"
 	"github.com/rajeshkumarblr/hn_station/internal/storage"
 	"golang.org/x/oauth2"
 )
@@ -25,15 +34,41 @@ type Server struct {
 	router   *chi.Mux
 	auth     *auth.Config
 	aiClient *ai.GeminiClient
+	embedder ai.Embedder
+	hnClient *hn.Client
+	live     *live.Hub
+	limiter  ratelimit.Limiter
+	limits   map[string]ratelimit.Limit
+	cache    *cache.Cache
+	articles *articles.Cache
+	fetcher  *content.Fetcher
+	github   *content.GitHubExtractor
+
+	publicBaseURL string // Origin used in absolute links, e.g. https://hnstation.dev
 }
 
-func NewServer(store *storage.Store, authCfg *auth.Config, aiClient *ai.GeminiClient) *Server {
+// NewServer wires up routes and middleware. embedder may be nil, in which
+// case semantic and hybrid search return 503.
+func NewServer(store *storage.Store, authCfg *auth.Config, aiClient *ai.GeminiClient, embedder ai.Embedder) *Server {
 	s := &Server{
 		store:    store,
 		router:   chi.NewRouter(),
 		auth:     authCfg,
 		aiClient: aiClient,
+		embedder: embedder,
+		hnClient: hn.NewClient(),
+		live:     live.NewHub(),
+		limiter:  ratelimit.NewMemoryLimiter(),
+		limits:   loadRateLimits(os.Getenv),
+		cache:    cache.New(responseCacheTTL, responseCacheEntries),
+		fetcher:  content.NewFetcher(nil),
+
+		publicBaseURL: strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/"),
 	}
+	s.github = content.GitHubFromEnv(os.Getenv, s.fetcher)
+	s.fetcher.Extractors = content.NewExtractors(s.github)
+	s.articles = articles.New(store, s.fetcher)
+	s.articles.Archive = content.ArchiveFromEnv(os.Getenv, s.fetcher)
 
 	s.middlewares()
 	s.routes()
@@ -43,10 +78,10 @@ func NewServer(store *storage.Store, authCfg *auth.Config, aiClient *ai.GeminiCl
 
 func (s *Server) middlewares() {
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
+	s.router.Use(realIP(parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))))
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.Timeout(60 * time.Second))
+	s.router.Use(requestTimeout(60 * time.Second))
 
 	allowedOrigins := []string{"http://localhost:5173", "https://hnstation.dev"}
 	s.router.Use(cors.Handler(cors.Options{
@@ -57,38 +92,78 @@ func (s *Server) middlewares() {
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
+	s.router.Use(s.requireActiveAccount)
 }
 
 func (s *Server) routes() {
 	// Health check
 	s.router.Get("/healthc", s.handleHealthCheck)
 
+	// Rate limits are per route group; see rateLimitGroups
+	apiRoutes := s.router.With(s.rateLimit(rateLimitAPI))
+	contentRoutes := s.router.With(s.rateLimit(rateLimitContent))
+	aiRoutes := s.router.With(s.rateLimit(rateLimitAI))
+	dataRoutes := s.router.With(s.rateLimit(rateLimitData))
+	authRoutes := s.router.With(s.rateLimit(rateLimitAuth))
+
 	// API routes
-	s.router.Get("/api/stories", s.handleGetStories)
-	s.router.Get("/api/stories/saved", s.handleGetSavedStories)
-	s.router.Get("/api/stories/{id}", s.handleGetStoryDetails)
-	s.router.Post("/api/stories/{id}/interact", s.handleInteract)
-	s.router.Get("/api/content/readme", s.handleGetReadme)
-	s.router.Get("/api/stories/{id}/content", s.handleGetArticleContent)
-	s.router.Get("/api/me", s.handleGetMe)
-	s.router.Post("/api/settings", s.handleUpdateSettings)
+	apiRoutes.Get("/api/stories", s.handleGetStories)
+	apiRoutes.Get("/api/live", s.handleLive)
+	apiRoutes.Get("/api/stories/saved", s.handleGetSavedStories)
+	apiRoutes.Get("/api/stories/by-url", s.handleGetStoriesByURL)
+	apiRoutes.Get("/api/stories/{id}", s.handleGetStoryDetails)
+	apiRoutes.Get("/api/stories/{id}/related", s.handleGetRelatedStories)
+	apiRoutes.Post("/api/stories/{id}/interact", s.handleInteract)
+	apiRoutes.Post("/api/stories/{id}/visit", s.handleRecordVisit)
+	apiRoutes.Post("/api/stories/{id}/tags", s.handleAddStoryTags)
+	apiRoutes.Delete("/api/stories/{id}/tags/{tag}", s.handleRemoveStoryTag)
+	apiRoutes.Get("/api/domains/top", s.handleGetTopDomains)
+	apiRoutes.Get("/api/domains/{domain}/stories", s.handleGetDomainStories)
+	contentRoutes.Get("/api/content/readme", s.handleGetReadme)
+	contentRoutes.Get("/api/stories/{id}/content", s.handleGetArticleContent)
+	apiRoutes.Get("/api/me", s.handleGetMe)
+	apiRoutes.Delete("/api/me", s.handleDeleteMe)
+	apiRoutes.Post("/api/me/restore", s.handleRestoreMe)
+	dataRoutes.Get("/api/me/data", s.handleGetMyData)
+	apiRoutes.Get("/api/me/feeds", s.handleGetFeedURLs)
+	apiRoutes.Post("/api/me/feeds/rotate", s.handleRotateFeedToken)
+	dataRoutes.Get("/api/me/export", s.handleExport)
+	dataRoutes.Post("/api/me/import", s.handleImport)
+	apiRoutes.Get("/api/me/tags", s.handleGetTags)
+	apiRoutes.Get("/api/me/folders", s.handleGetFolders)
+	apiRoutes.Get("/api/me/filters", s.handleGetFilters)
+	apiRoutes.Post("/api/me/filters", s.handleCreateFilter)
+	apiRoutes.Put("/api/me/filters/{filterID}", s.handleUpdateFilter)
+	apiRoutes.Delete("/api/me/filters/{filterID}", s.handleDeleteFilter)
+	apiRoutes.Get("/api/me/watchlist", s.handleGetWatchlist)
+	apiRoutes.Post("/api/me/watchlist", s.handleCreateWatchlistEntry)
+	apiRoutes.Delete("/api/me/watchlist/{watchID}", s.handleDeleteWatchlistEntry)
+	apiRoutes.Get("/api/notifications", s.handleGetNotifications)
+	apiRoutes.Post("/api/notifications/read", s.handleMarkNotifications)
+	apiRoutes.Post("/api/settings", s.handleUpdateSettings)
+
+	// Feeds (RSS, Atom, JSON Feed)
+	s.registerFeedRoutes()
 
 	// Auth routes
-	s.router.Get("/auth/google", s.handleGoogleLogin)
-	s.router.Get("/auth/google/callback", s.handleGoogleCallback)
-	s.router.Get("/auth/logout", s.handleLogout)
+	authRoutes.Get("/auth/google", s.handleGoogleLogin)
+	authRoutes.Get("/auth/google/callback", s.handleGoogleCallback)
+	authRoutes.Get("/auth/logout", s.handleLogout)
 
 	// AI routes
-	s.router.Post("/api/stories/{id}/summarize", s.handleSummarizeStory)
-	s.router.Post("/api/stories/{id}/summarize_article", s.handleSummarizeArticle)
-	s.router.Get("/api/chat/{id}", s.handleGetChatHistory)
-	s.router.Post("/api/chat", s.handleChat)
+	aiRoutes.Post("/api/stories/{id}/summarize", s.handleSummarizeStory)
+	aiRoutes.Post("/api/stories/{id}/summarize/stream", s.handleSummarizeStoryStream)
+	aiRoutes.Post("/api/stories/{id}/summarize_article", s.handleSummarizeArticle)
+	apiRoutes.Get("/api/chat/{id}", s.handleGetChatHistory)
+	aiRoutes.Post("/api/chat", s.handleChat)
+	aiRoutes.Post("/api/chat/stream", s.handleChatStream)
 
 	// Admin routes
 	s.router.Group(func(r chi.Router) {
 		r.Use(s.adminMiddleware)
 		r.Get("/api/admin/stats", s.handleGetAdminStats)
 		r.Get("/api/admin/users", s.handleGetAdminUsers)
+		r.Get("/api/admin/cache", s.handleGetCacheStats)
 	})
 
 	// SPA catch-all
@@ -295,53 +370,77 @@ func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
 
 // ─── Story Handlers ───
 
-func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+// storyListParams are the query parameters shared by /api/stories and the
+// /feeds/stories.* feeds.
+type storyListParams struct {
+	Limit     int
+	Offset    int
+	Sort      string
+	Topics    []string
+	MinPoints int
+}
 
-	limit := 20
-	offset := 0
+// maxStoryListLimit bounds ?limit=, which is also part of the response cache key.
+const maxStoryListLimit = 100
 
-	if limitStr != "" {
-		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
-			limit = val
-		}
+func parseStoryListParams(r *http.Request, defaultLimit int) storyListParams {
+	q := r.URL.Query()
+	p := storyListParams{Limit: defaultLimit, Sort: "default"}
+
+	if val, err := strconv.Atoi(q.Get("limit")); err == nil && val > 0 {
+		p.Limit = min(val, maxStoryListLimit)
 	}
-	if offsetStr != "" {
-		if val, err := strconv.Atoi(offsetStr); err == nil && val >= 0 {
-			offset = val
-		}
+	if val, err := strconv.Atoi(q.Get("offset")); err == nil && val >= 0 {
+		p.Offset = val
 	}
-
-	// Semantic search path - DISABLED for Gemini BYOK MVP
-	searchType := r.URL.Query().Get("type")
-	if searchType == "semantic" {
-		http.Error(w, "Semantic search is currently disabled in BYOK mode", http.StatusServiceUnavailable)
-		return
+	if val, err := strconv.Atoi(q.Get("min_points")); err == nil && val > 0 {
+		p.MinPoints = val
 	}
 
-	sortParam := r.URL.Query().Get("sort")
-	if sortParam == "new" {
-		sortParam = "latest"
+	// "list" is accepted as an alias so feed URLs can mirror HN's list names
+	sortParam := q.Get("sort")
+	if sortParam == "" {
+		sortParam = q.Get("list")
 	}
-
-	if sortParam != "latest" && sortParam != "votes" && sortParam != "default" && sortParam != "show" {
-		sortParam = "default"
+	switch sortParam {
+	case "new", "newest", "latest":
+		p.Sort = "latest"
+	case "votes", "best":
+		p.Sort = "votes"
+	case "show":
+		p.Sort = "show"
 	}
 
-	topicParams := r.URL.Query()["topic"]
-	var topics []string
-	for _, t := range topicParams {
+	for _, t := range q["topic"] {
 		if strings.TrimSpace(t) != "" {
-			topics = append(topics, t)
+			p.Topics = append(p.Topics, t)
 		}
 	}
+	return p
+}
+
+func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
+	params := parseStoryListParams(r, 20)
+
+	// Semantic / hybrid search path
+	searchType := r.URL.Query().Get("type")
+	if searchType == "semantic" || searchType == "hybrid" {
+		s.handleSemanticSearch(w, r, searchType, params.Limit, params.Offset)
+		return
+	}
 
 	// Pass user ID for interaction flags (empty string = anonymous)
 	userID := s.auth.GetUserIDFromRequest(r)
 	showHidden := r.URL.Query().Get("show_hidden") == "true"
 
-	stories, err := s.store.GetStories(r.Context(), limit, offset, sortParam, topics, userID, showHidden)
+	// Anonymous responses are the same for everyone and cached per ingest run
+	cacheKey := params.cacheKey()
+	version := s.cache.Version()
+	if userID == "" && s.serveCached(w, r, cacheNamespaceStories, cacheKey) {
+		return
+	}
+
+	stories, err := s.store.GetStories(r.Context(), params.Limit, params.Offset, params.Sort, params.Topics, params.MinPoints, userID, showHidden)
 	if err != nil {
 		http.Error(w, "Failed to fetch stories", http.StatusInternalServerError)
 		return
@@ -351,6 +450,51 @@ func (s *Server) handleGetStories(w http.ResponseWriter, r *http.Request) {
 		stories = []storage.Story{}
 	}
 
+	s.respondJSON(w, r, stories, userID != "", cacheNamespaceStories, cacheKey, version)
+}
+
+// handleSemanticSearch serves /api/stories?type=semantic|hybrid&q=...
+// Without q, the topic parameters are used as the query text.
+func (s *Server) handleSemanticSearch(w http.ResponseWriter, r *http.Request, searchType string, limit, offset int) {
+	if s.embedder == nil {
+		http.Error(w, "Semantic search is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	queryText := strings.TrimSpace(r.URL.Query().Get("q"))
+	if queryText == "" {
+		queryText = strings.TrimSpace(strings.Join(r.URL.Query()["topic"], " "))
+	}
+	if queryText == "" {
+		http.Error(w, "q parameter required", http.StatusBadRequest)
+		return
+	}
+
+	vec, err := s.embedder.EmbedQuery(r.Context(), queryText)
+	if err != nil {
+		log.Printf("Failed to embed search query: %v", err)
+		http.Error(w, "Failed to embed query", http.StatusBadGateway)
+		return
+	}
+	embedding := pgvector.NewVector(vec)
+
+	userID := s.auth.GetUserIDFromRequest(r)
+	var stories []storage.Story
+	if searchType == "hybrid" {
+		stories, err = s.store.HybridSearchStories(r.Context(), queryText, embedding, limit, offset, s.embedder.MinSimilarity(), userID)
+	} else {
+		stories, err = s.store.SearchStories(r.Context(), embedding, limit, offset, s.embedder.MinSimilarity(), userID)
+	}
+	if err != nil {
+		log.Printf("Semantic search failed: %v", err)
+		http.Error(w, "Failed to search stories", http.StatusInternalServerError)
+		return
+	}
+
+	if stories == nil {
+		stories = []storage.Story{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stories)
 }
@@ -363,6 +507,13 @@ func (s *Server) handleGetStoryDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID := s.auth.GetUserIDFromRequest(r)
+	cacheKey := strconv.Itoa(id)
+	version := s.cache.Version()
+	if userID == "" && s.serveCached(w, r, cacheNamespaceStory, cacheKey) {
+		return
+	}
+
 	story, err := s.store.GetStory(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Story not found", http.StatusNotFound)
@@ -378,17 +529,85 @@ func (s *Server) handleGetStoryDetails(w http.ResponseWriter, r *http.Request) {
 	if comments == nil {
 		comments = []storage.Comment{}
 	}
+	for i := range comments {
+		comments[i].Text = content.Sanitize(comments[i].Text, content.HNBaseURL)
+	}
+
+	// For logged-in users, flag comments posted since the last visit. Reading
+	// has no side effects; clients advance the watermark with POST .../visit.
+	var newCount *int
+	var lastVisited *time.Time
+	if userID != "" {
+		visit, err := s.store.GetStoryVisit(r.Context(), userID, id)
+		if err != nil {
+			log.Printf("Failed to fetch story visit: %v", err)
+		} else if visit != nil {
+			newCount = flagNewComments(comments, visit.LastSeenCommentID)
+			lastVisited = &visit.LastVisitedAt
+		}
+	}
 
 	response := struct {
-		Story    *storage.Story    `json:"story"`
-		Comments []storage.Comment `json:"comments"`
+		Story         *storage.Story    `json:"story"`
+		Comments      []storage.Comment `json:"comments"`
+		NewComments   *int              `json:"new_comments,omitempty"`
+		LastVisitedAt *time.Time        `json:"last_visited_at,omitempty"`
 	}{
-		Story:    story,
-		Comments: comments,
+		Story:         story,
+		Comments:      comments,
+		NewComments:   newCount,
+		LastVisitedAt: lastVisited,
+	}
+
+	s.respondJSON(w, r, response, userID != "", cacheNamespaceStory, cacheKey, version)
+}
+
+// flagNewComments marks comments above the watermark as new and returns how
+// many there are. Story lists count new_comments the same way.
+func flagNewComments(comments []storage.Comment, lastSeenCommentID int64) *int {
+	newCount := 0
+	for i := range comments {
+		if comments[i].ID > lastSeenCommentID {
+			comments[i].IsNew = true
+			newCount++
+		}
+	}
+	return &newCount
+}
+
+// handleRecordVisit advances the user's comment watermark for a story once
+// the client has shown its discussion. The optional body
+// {"last_comment_id": N} names the newest comment shown, so comments that
+// arrived in between stay new; without it the newest stored comment is used.
+func (s *Server) handleRecordVisit(w http.ResponseWriter, r *http.Request) {
+	userID := s.auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	storyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		LastCommentID int64 `json:"last_comment_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); (err != nil && !errors.Is(err, io.EOF)) || body.LastCommentID < 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.RecordStoryVisit(r.Context(), userID, storyID, body.LastCommentID); err != nil {
+		log.Printf("Failed to record story visit: %v", err)
+		http.Error(w, "Failed to record visit", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
 // ─── Interaction Handlers ───
@@ -408,9 +627,11 @@ func (s *Server) handleInteract(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		Read   *bool `json:"read"`
-		Saved  *bool `json:"saved"`
-		Hidden *bool `json:"hidden"`
+		Read   *bool   `json:"read"`
+		Saved  *bool   `json:"saved"`
+		Hidden *bool   `json:"hidden"`
+		Note   *string `json:"note"`
+		Folder *string `json:"folder"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -423,6 +644,13 @@ func (s *Server) handleInteract(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if body.Note != nil || body.Folder != nil {
+		if err := s.store.UpdateSavedAnnotations(r.Context(), userID, storyID, body.Note, body.Folder); err != nil {
+			writeSavedError(w, err)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
@@ -450,7 +678,13 @@ func (s *Server) handleGetSavedStories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	stories, err := s.store.GetSavedStories(r.Context(), userID, limit, offset)
+	filter := storage.SavedStoriesFilter{
+		Tag:    r.URL.Query().Get("tag"),
+		Folder: r.URL.Query().Get("folder"),
+		Query:  strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+
+	stories, err := s.store.GetSavedStories(r.Context(), userID, filter, limit, offset)
 	if err != nil {
 		http.Error(w, "Failed to fetch saved stories", http.StatusInternalServerError)
 		return
@@ -464,101 +698,125 @@ func (s *Server) handleGetSavedStories(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stories)
 }
 
-func (s *Server) handleSummarizeStory(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid story ID", http.StatusBadRequest)
-		return
-	}
-
+// geminiUser returns the logged-in user if they have a Gemini API key set,
+// writing the error response otherwise.
+func (s *Server) geminiUser(w http.ResponseWriter, r *http.Request) (*storage.AuthUser, bool) {
 	userID := s.auth.GetUserIDFromRequest(r)
 	if userID == "" {
 		http.Error(w, "Authentication required", http.StatusUnauthorized)
-		return
+		return nil, false
 	}
 
 	user, err := s.store.GetAuthUser(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "User not found", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	if user.GeminiAPIKey == "" {
 		http.Error(w, "Please set your Gemini API Key in Settings to use this feature.", http.StatusBadRequest)
-		return
+		return nil, false
 	}
+	return user, true
+}
 
-	story, err := s.store.GetStory(r.Context(), id)
+// discussionText formats a story's comments as prompt context, truncated to
+// roughly maxChars to avoid excessive processing time.
+func discussionText(header string, comments []storage.Comment, maxChars int) string {
+	var sb strings.Builder
+	sb.WriteString(header)
+
+	totalChars := 0
+	for _, c := range comments {
+		text := fmt.Sprintf("- %s: %s\n", c.By, c.Text)
+		if totalChars+len(text) > maxChars {
+			break
+		}
+		sb.WriteString(text)
+		totalChars += len(text)
+	}
+	return sb.String()
+}
+
+// saveSummary stores a generated summary in the global cache (stories table)
+// and in the user's chat history for the story.
+func (s *Server) saveSummary(ctx context.Context, userID string, story *storage.Story, summary string) {
+	if err := s.store.UpdateStorySummary(ctx, int(story.ID), summary); err != nil {
+		log.Printf("Failed to update story summary cache: %v", err)
+	}
+	s.invalidateStory(int(story.ID))
+
+	if err := s.store.SaveChatMessage(ctx, userID, int(story.ID), "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, summary)); err != nil {
+		log.Printf("Failed to save summary to history: %v", err)
+		// Don't fail the request, just log
+	}
+}
+
+// summaryInput loads what handleSummarizeStory and its streaming variant need.
+// If the story already has a cached summary, or has no discussion, the
+// returned summary is final and prompt is empty.
+func (s *Server) summaryInput(w http.ResponseWriter, r *http.Request, userID string) (story *storage.Story, summary, prompt string, ok bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return nil, "", "", false
+	}
+
+	story, err = s.store.GetStory(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Story not found", http.StatusNotFound)
-		return
+		return nil, "", "", false
 	}
 
 	// 1. Check Global Cache (Short-circuit if already summarized)
 	if story.Summary != nil && *story.Summary != "" {
-		// Save to chat history so user sees it in their thread too (if not already there)
-		// We can't easily check if it's there without fetching history, but duplicate consecutive messages are fine-ish.
-		// Better: just return it. The frontend 'fetchHistory' will see the summary if we don't add it here?
-		// No, frontend logic: if history empty, check story.summary.
-		// So we don't strictly need to add to history if it's in story.summary, BUT for consistency in chat view we might want it.
-		// Let's add it to history to be safe and consistent with "Summarize" action.
+		// Add it to history to be consistent with the "Summarize" action
 		if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, *story.Summary)); err != nil {
 			log.Printf("Failed to save cached summary to history: %v", err)
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"summary": *story.Summary})
-		return
+		return story, *story.Summary, "", true
 	}
 
 	comments, err := s.store.GetComments(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
-		return
+		return nil, "", "", false
 	}
 
 	if len(comments) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"summary": "No discussion to summarize."})
-		return
+		return story, "No discussion to summarize.", "", true
 	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Title: %s\n\nDiscussion:\n", story.Title))
-
-	// Limit to reasonable amount of text to avoid excessive processing time
-	// A naive truncation strategy
-	totalChars := 0
-	maxChars := 12000 // roughly 3-4k tokens
+	// roughly 3-4k tokens
+	prompt = discussionText(fmt.Sprintf("Title: %s\n\nDiscussion:\n", story.Title), comments, 12000)
+	return story, "", prompt, true
+}
 
-	for _, c := range comments {
-		text := fmt.Sprintf("- %s: %s\n", c.By, c.Text)
-		if totalChars+len(text) > maxChars {
-			break
-		}
-		sb.WriteString(text)
-		totalChars += len(text)
+func (s *Server) handleSummarizeStory(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.geminiUser(w, r)
+	if !ok {
+		return
 	}
 
-	// Pass user's API key
-	summary, err := s.aiClient.GenerateSummary(r.Context(), user.GeminiAPIKey, sb.String())
-	if err != nil {
-		log.Printf("Summarization failed: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate summary: " + err.Error()})
+	story, summary, prompt, ok := s.summaryInput(w, r, user.ID)
+	if !ok {
 		return
 	}
 
-	// 2. Save to Global Cache (Stories table)
-	if err := s.store.UpdateStorySummary(r.Context(), id, summary); err != nil {
-		log.Printf("Failed to update story summary cache: %v", err)
-	}
+	if prompt != "" {
+		// Pass user's API key
+		var err error
+		summary, err = s.aiClient.GenerateSummary(r.Context(), user.GeminiAPIKey, prompt)
+		if err != nil {
+			log.Printf("Summarization failed: %v", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to generate summary: " + err.Error()})
+			return
+		}
 
-	// Save summary to chat history
-	if err := s.store.SaveChatMessage(r.Context(), userID, id, "model", fmt.Sprintf("**Summary of \"%s\":**\n\n%s", story.Title, summary)); err != nil {
-		log.Printf("Failed to save summary to history: %v", err)
-		// Don't fail the request, just log
+		// 2. Save to Global Cache and chat history
+		s.saveSummary(r.Context(), user.ID, story, summary)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -589,56 +847,42 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
-	userID := s.auth.GetUserIDFromRequest(r)
-	if userID == "" {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
-		return
-	}
-
-	user, err := s.store.GetAuthUser(r.Context(), userID)
-	if err != nil {
-		http.Error(w, "User not found", http.StatusInternalServerError)
-		return
-	}
-
-	if user.GeminiAPIKey == "" {
-		http.Error(w, "Please set your Gemini API Key in Settings to use this feature.", http.StatusBadRequest)
-		return
-	}
+type chatRequest struct {
+	StoryID int    `json:"story_id"`
+	Message string `json:"message"`
+}
 
-	var body struct {
-		StoryID int    `json:"story_id"`
-		Message string `json:"message"`
-	}
+// prepareChat decodes a chat request, saves the user's message and returns the
+// story context and prior history (excluding the new message) for the model.
+func (s *Server) prepareChat(w http.ResponseWriter, r *http.Request, userID string) (body chatRequest, contextText string, history []ai.ChatMessage, ok bool) {
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return body, "", nil, false
 	}
 
 	if body.Message == "" {
 		http.Error(w, "Message is required", http.StatusBadRequest)
-		return
+		return body, "", nil, false
 	}
 
 	// Save user message
 	if err := s.store.SaveChatMessage(r.Context(), userID, body.StoryID, "user", body.Message); err != nil {
 		log.Printf("Failed to save user message: %v", err)
 		http.Error(w, "Failed to save message", http.StatusInternalServerError)
-		return
+		return body, "", nil, false
 	}
 
 	// Fetch story context
 	story, err := s.store.GetStory(r.Context(), body.StoryID)
 	if err != nil {
 		http.Error(w, "Story not found", http.StatusNotFound)
-		return
+		return body, "", nil, false
 	}
 
 	comments, err := s.store.GetComments(r.Context(), body.StoryID)
 	if err != nil {
 		http.Error(w, "Failed to fetch comments", http.StatusInternalServerError)
-		return
+		return body, "", nil, false
 	}
 
 	// Fetch chat history from DB
@@ -646,47 +890,38 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Failed to fetch chat history: %v", err)
 		http.Error(w, "Failed to fetch history", http.StatusInternalServerError)
-		return
+		return body, "", nil, false
 	}
 
 	// Convert DB history to AI client history
-	var aiHistory []ai.ChatMessage
 	for _, msg := range dbHistory {
-		aiHistory = append(aiHistory, ai.ChatMessage{
+		history = append(history, ai.ChatMessage{
 			Role:    msg.Role,
 			Content: msg.Content,
 		})
 	}
-	// Note: aiHistory now includes the message we just saved.
-	// GenerateChatResponse expects history EXCLUDING the new message, and the new message as separate arg?
-	// Actually, looking at client.go: GenerateChatResponse(ctx, apiKey, context, history, newMessage)
-	// So we should exclude the very last user message from 'history' passed to AI,
-	// OR just pass it as 'newMessage' and existing history.
-
-	// Let's filter aiHistory to exclude the last message we just added?
-	// No, better to just use the `body.Message` as new message.
-	// But `aiHistory` contains it now.
-	// We need to pass `aiHistory` WITHOUT the last message to the client, if we want to follow that pattern.
-	// OR, we can just pass `aiHistory` excluding the last item.
+	// The history now ends with the message we just saved, which the client
+	// sends separately as the new message.
+	if len(history) > 0 {
+		history = history[:len(history)-1]
+	}
 
-	effectiveHistory := aiHistory[:len(aiHistory)-1]
+	contextText = discussionText(fmt.Sprintf("Title: %s\nURL: %s\n\nDiscussion:\n", story.Title, story.URL), comments, 15000)
+	return body, contextText, history, true
+}
 
-	// Prepare context text
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Title: %s\nURL: %s\n\nDiscussion:\n", story.Title, story.URL))
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.geminiUser(w, r)
+	if !ok {
+		return
+	}
 
-	totalChars := 0
-	maxChars := 15000
-	for _, c := range comments {
-		text := fmt.Sprintf("- %s: %s\n", c.By, c.Text)
-		if totalChars+len(text) > maxChars {
-			break
-		}
-		sb.WriteString(text)
-		totalChars += len(text)
+	body, contextText, history, ok := s.prepareChat(w, r, user.ID)
+	if !ok {
+		return
 	}
 
-	response, err := s.aiClient.GenerateChatResponse(r.Context(), user.GeminiAPIKey, sb.String(), effectiveHistory, body.Message)
+	response, err := s.aiClient.GenerateChatResponse(r.Context(), user.GeminiAPIKey, contextText, history, body.Message)
 	if err != nil {
 		log.Printf("Chat generation failed: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -696,7 +931,7 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save model response
-	if err := s.store.SaveChatMessage(r.Context(), userID, body.StoryID, "model", response); err != nil {
+	if err := s.store.SaveChatMessage(r.Context(), user.ID, body.StoryID, "model", response); err != nil {
 		log.Printf("Failed to save model response: %v", err)
 		// Don't fail, return response
 	}
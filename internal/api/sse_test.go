@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEWriter(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sse, err := newSSEWriter(rr)
+	assert.NoError(t, err)
+
+	assert.NoError(t, sse.Event("token", map[string]string{"text": "hi\nthere"}))
+	assert.NoError(t, sse.Ping())
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "event: token\ndata: {\"text\":\"hi\\nthere\"}\n\n: ping\n\n", rr.Body.String())
+	assert.True(t, rr.Flushed)
+}
+
+func TestStreamEndpoints_RequireAuth(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+
+	for _, path := range []string{"/api/chat/stream", "/api/stories/1/summarize/stream"} {
+		req, _ := http.NewRequest("POST", path, nil)
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, path)
+	}
+}
+
+func TestLive_RejectsInvalidStory(t *testing.T) {
+	server := NewServer(nil, nil, nil, nil)
+
+	req, _ := http.NewRequest("GET", "/api/live?story=abc", nil)
+	rr := httptest.NewRecorder()
+	server.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
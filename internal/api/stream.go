@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// streamTimeout bounds streaming generations, which are exempt from the
+// regular request timeout.
+const streamTimeout = 3 * time.Minute
+
+// isStreamingRequest reports whether a request is served as a long-lived stream.
+func isStreamingRequest(r *http.Request) bool {
+	return strings.HasSuffix(r.URL.Path, "/stream") || r.URL.Path == "/api/live"
+}
+
+// requestTimeout applies middleware.Timeout to everything except streaming
+// endpoints, which set their own deadlines.
+func requestTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		timed := middleware.Timeout(d)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isStreamingRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
+
+// handleSummarizeStoryStream is the SSE variant of handleSummarizeStory.
+// Events: "token" {"text"} for each chunk, then "done" {"summary"} or "error" {"error"}.
+// A cached summary is sent as a single "done" event.
+func (s *Server) handleSummarizeStoryStream(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.geminiUser(w, r)
+	if !ok {
+		return
+	}
+
+	story, summary, prompt, ok := s.summaryInput(w, r, user.ID)
+	if !ok {
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if prompt == "" {
+		sse.Event("done", map[string]string{"summary": summary})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), streamTimeout)
+	defer cancel()
+
+	summary, err = s.aiClient.StreamSummary(ctx, user.GeminiAPIKey, prompt, func(text string) error {
+		return sse.Event("token", map[string]string{"text": text})
+	})
+	if err != nil {
+		log.Printf("Streaming summarization failed: %v", err)
+		sse.Event("error", map[string]string{"error": "Failed to generate summary: " + err.Error()})
+		return
+	}
+
+	// Persist even if the client has gone away, so the work is not lost
+	s.saveSummary(context.WithoutCancel(r.Context()), user.ID, story, summary)
+	sse.Event("done", map[string]string{"summary": summary})
+}
+
+// handleChatStream is the SSE variant of handleChat.
+// Events: "token" {"text"} for each chunk, then "done" {"response"} or "error" {"error"}.
+func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.geminiUser(w, r)
+	if !ok {
+		return
+	}
+
+	body, contextText, history, ok := s.prepareChat(w, r, user.ID)
+	if !ok {
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), streamTimeout)
+	defer cancel()
+
+	response, err := s.aiClient.StreamChatResponse(ctx, user.GeminiAPIKey, contextText, history, body.Message, func(text string) error {
+		return sse.Event("token", map[string]string{"text": text})
+	})
+	if err != nil {
+		log.Printf("Streaming chat failed: %v", err)
+		sse.Event("error", map[string]string{"error": "Failed to generate response: " + err.Error()})
+		return
+	}
+
+	// Save model response
+	if err := s.store.SaveChatMessage(context.WithoutCancel(r.Context()), user.ID, body.StoryID, "model", response); err != nil {
+		log.Printf("Failed to save model response: %v", err)
+	}
+	sse.Event("done", map[string]string{"response": response})
+}
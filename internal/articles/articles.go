@@ -0,0 +1,368 @@
+// Package articles serves extracted article content from the
+// article_contents table, fetching from the origin only when the cached copy
+// is missing or older than its TTL.
+package articles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	DefaultTTL        = 24 * time.Hour
+	DefaultFailureTTL = time.Hour // Retry dead links at most hourly
+)
+
+// ErrUnavailable is returned when the article could not be fetched and no
+// earlier copy exists.
+var ErrUnavailable = errors.New("article content unavailable")
+
+// BlockedError is returned instead of ErrUnavailable, which it matches,
+// when the page was a paywall, login wall, bot check or consent page.
+type BlockedError struct {
+	Outcome content.Outcome
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("article content unavailable: %s", e.Outcome)
+}
+
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// Store is the subset of storage.Store used by the cache.
+type Store interface {
+	GetArticleContent(ctx context.Context, storyID int64) (*storage.ArticleContent, error)
+	SaveArticleContent(ctx context.Context, a *storage.ArticleContent) (*storage.ArticleContent, error)
+	RecordArchiveMiss(ctx context.Context, storyID int64, url string) error
+}
+
+// Fetcher fetches and extracts an article; *content.Fetcher implements it.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string, opts content.Options) (*content.FetchResult, error)
+}
+
+// Archive fetches archived copies of pages; *content.Archive implements it.
+type Archive interface {
+	Fetch(ctx context.Context, url string) (*content.FetchResult, error)
+}
+
+// Sources reported by Resolve.
+const (
+	SourceOrigin  = "origin"  // Extracted from the page itself
+	SourceArchive = "archive" // Extracted from an archived snapshot
+	SourceCache   = "cache"   // An earlier extract, kept because both failed
+)
+
+// Cache is safe for concurrent use. Concurrent misses for the same story
+// share a single origin fetch.
+type Cache struct {
+	TTL        time.Duration
+	FailureTTL time.Duration
+	Archive    Archive // Fallback for pages that are gone; nil disables it
+
+	store   Store
+	fetcher Fetcher
+	now     func() time.Time
+
+	mu       sync.Mutex
+	inflight map[int64]*call
+}
+
+type call struct {
+	done    chan struct{}
+	article *storage.ArticleContent
+	err     error
+}
+
+// New returns a cache with the default TTLs.
+func New(store Store, fetcher Fetcher) *Cache {
+	return &Cache{
+		TTL:        DefaultTTL,
+		FailureTTL: DefaultFailureTTL,
+		store:      store,
+		fetcher:    fetcher,
+		now:        time.Now,
+		inflight:   make(map[int64]*call),
+	}
+}
+
+// Get returns the content for a story's URL. A stale copy is returned
+// (with Status failed) when a refresh fails; ErrUnavailable when there is
+// nothing to show.
+func (c *Cache) Get(ctx context.Context, storyID int64, url string) (*storage.ArticleContent, error) {
+	cached, err := c.store.GetArticleContent(ctx, storyID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if cached != nil && cached.URL == url && c.fresh(cached) {
+		return usable(cached)
+	}
+
+	c.mu.Lock()
+	if inflight, ok := c.inflight[storyID]; ok {
+		c.mu.Unlock()
+		select {
+		case <-inflight.done:
+			// The fetching request went away; try again on our own context
+			if errors.Is(inflight.err, context.Canceled) && ctx.Err() == nil {
+				return c.Get(ctx, storyID, url)
+			}
+			return inflight.article, inflight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	cl := &call{done: make(chan struct{})}
+	c.inflight[storyID] = cl
+	c.mu.Unlock()
+
+	cl.article, cl.err = c.refresh(ctx, storyID, url)
+	if cl.err == nil {
+		cl.article, cl.err = usable(cl.article)
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, storyID)
+	c.mu.Unlock()
+	close(cl.done)
+
+	return cl.article, cl.err
+}
+
+func (c *Cache) fresh(a *storage.ArticleContent) bool {
+	ttl := c.TTL
+	if a.Status != storage.ArticleStatusOK {
+		ttl = c.FailureTTL
+	}
+	return c.now().Sub(a.FetchedAt) < ttl
+}
+
+// refresh fetches the article and records the outcome. Cancellation is not
+// recorded as a failure.
+func (c *Cache) refresh(ctx context.Context, storyID int64, url string) (*storage.ArticleContent, error) {
+	record := &storage.ArticleContent{StoryID: storyID, URL: url, Status: storage.ArticleStatusOK}
+
+	result, err := c.fetcher.Fetch(ctx, url, content.Options{})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	switch {
+	case err != nil:
+		msg := err.Error()
+		record.Status, record.Error = storage.ArticleStatusFailed, &msg
+		record.Outcome = string(content.OutcomeError)
+	case result.StatusCode >= 400:
+		msg := fmt.Sprintf("HTTP %d", result.StatusCode)
+		record.Status, record.Error = storage.ArticleStatusFailed, &msg
+		record.HTTPStatus = &result.StatusCode
+		record.Outcome = string(result.Outcome)
+	case result.Outcome.Blocked():
+		// A wall is a failure: keep any earlier copy and retry after FailureTTL
+		msg := string(result.Outcome)
+		record.Status, record.Error = storage.ArticleStatusFailed, &msg
+		record.HTTPStatus = &result.StatusCode
+		record.Outcome = string(result.Outcome)
+		if result.Outcome == content.OutcomePaywall {
+			// Teaser pages carry the article's own preview metadata
+			record.Preview = preview(result.Metadata)
+		}
+	default:
+		record.Title = result.Title
+		record.ContentHTML = result.Content
+		record.ContentText = result.Text
+		record.CanIframe = result.CanIframe
+		record.Preview = preview(result.Metadata)
+		if result.StatusCode != 0 {
+			record.HTTPStatus = &result.StatusCode
+		}
+	}
+
+	return c.store.SaveArticleContent(ctx, record)
+}
+
+// preview converts extracted metadata, storing absent fields as NULL.
+func preview(m content.Metadata) storage.Preview {
+	return storage.Preview{
+		Description: nonEmpty(m.Description),
+		ImageURL:    nonEmpty(m.Image),
+		SiteName:    nonEmpty(m.SiteName),
+		Author:      nonEmpty(m.Author),
+		PublishedAt: m.PublishedAt,
+		FaviconURL:  nonEmpty(m.Favicon),
+	}
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Resolve returns the best available content for a story's URL and where it
+// came from: the origin, then an archived snapshot when the origin fails,
+// then our own earlier extract. For links known to be dead the archive is
+// asked first, but the origin is still tried when it has no snapshot, in
+// case the link check was wrong. An archived extract is cached like an
+// origin one, and a lookup that finds no usable snapshot is not repeated
+// for FailureTTL. Walls are not worked around: a walled origin returns a
+// BlockedError, or our earlier extract when there is one.
+func (c *Cache) Resolve(ctx context.Context, storyID int64, url string, dead bool) (*storage.ArticleContent, string, error) {
+	var article *storage.ArticleContent
+	unavailable := ErrUnavailable
+	archiveMissed := false
+	// Recorded on the way out: a dead link may have no row until Get saves one
+	defer func() {
+		if archiveMissed {
+			if err := c.store.RecordArchiveMiss(ctx, storyID, url); err != nil {
+				log.Printf("Failed to record archive miss for story %d: %v", storyID, err)
+			}
+		}
+	}()
+	if dead {
+		// Reuse a recent snapshot extract before asking the archive again
+		cached, err := c.store.GetArticleContent(ctx, storyID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, "", err
+		}
+		if cached != nil && cached.URL == url && cached.ContentHTML != "" {
+			if cached.Source == storage.ArticleSourceArchive && c.fresh(cached) {
+				return cached, SourceArchive, nil
+			}
+			article = cached
+		}
+		archived, missed, err := c.tryArchive(ctx, storyID, url, article)
+		if archived != nil || err != nil {
+			return archived, SourceArchive, err
+		}
+		archiveMissed = missed
+	}
+
+	got, err := c.Get(ctx, storyID, url)
+	var blocked *BlockedError
+	switch {
+	case err == nil && got.Status == storage.ArticleStatusOK:
+		return got, source(got), nil
+	case err == nil && content.Outcome(got.Outcome).Blocked():
+		return got, SourceCache, nil
+	case err == nil:
+		article = got
+	case errors.As(err, &blocked):
+		return nil, "", err
+	case errors.Is(err, ErrUnavailable):
+		unavailable = err
+	default:
+		return nil, "", err
+	}
+
+	if !dead {
+		archived, missed, err := c.tryArchive(ctx, storyID, url, article)
+		if archived != nil || err != nil {
+			return archived, SourceArchive, err
+		}
+		archiveMissed = missed
+	}
+
+	if article == nil {
+		return nil, "", unavailable
+	}
+	return article, SourceCache, nil
+}
+
+// tryArchive returns the archived extract, or nil when there is no usable
+// snapshot; missed reports a lookup that found none. The archive is not
+// asked again within FailureTTL of a miss. Only cancellation is returned as
+// an error.
+func (c *Cache) tryArchive(ctx context.Context, storyID int64, url string, prev *storage.ArticleContent) (archived *storage.ArticleContent, missed bool, err error) {
+	if c.Archive == nil {
+		return nil, false, nil
+	}
+	row, err := c.store.GetArticleContent(ctx, storyID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, false, err
+	}
+	if row != nil && row.URL == url && row.ArchiveMissedAt != nil && c.now().Sub(*row.ArchiveMissedAt) < c.FailureTTL {
+		return nil, false, nil
+	}
+
+	archived, err = c.fromArchive(ctx, storyID, url, prev)
+	if err == nil {
+		return archived, false, nil
+	}
+	if ctx.Err() != nil {
+		return nil, false, ctx.Err()
+	}
+	log.Printf("Archive fallback failed for %s: %v", url, err)
+	return nil, true, nil
+}
+
+func source(a *storage.ArticleContent) string {
+	if a.Source == storage.ArticleSourceArchive {
+		return SourceArchive
+	}
+	return SourceOrigin
+}
+
+// fromArchive extracts the latest snapshot and caches it, keeping the
+// preview of the live page when there is one.
+func (c *Cache) fromArchive(ctx context.Context, storyID int64, url string, prev *storage.ArticleContent) (*storage.ArticleContent, error) {
+	result, err := c.Archive.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := result.URL
+	record := &storage.ArticleContent{
+		StoryID:     storyID,
+		URL:         url,
+		Title:       result.Title,
+		ContentHTML: result.Content,
+		ContentText: result.Text,
+		CanIframe:   false, // The origin is gone
+		Status:      storage.ArticleStatusOK,
+		Source:      storage.ArticleSourceArchive,
+		ArchiveURL:  &snapshot,
+	}
+	if result.StatusCode != 0 {
+		record.HTTPStatus = &result.StatusCode
+	}
+	if prev != nil {
+		record.Preview = prev.Preview
+	}
+
+	saved, err := c.store.SaveArticleContent(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	return usable(saved)
+}
+
+// usable returns ErrUnavailable for records without any content, or a
+// BlockedError when a wall is all the origin served.
+func usable(a *storage.ArticleContent) (*storage.ArticleContent, error) {
+	if a.ContentHTML == "" {
+		if o := content.Outcome(a.Outcome); o.Blocked() {
+			return nil, &BlockedError{Outcome: o}
+		}
+		return nil, ErrUnavailable
+	}
+	return a, nil
+}
+
+// Text returns the plain-text rendition of an article, deriving it from the
+// HTML for rows stored without one.
+func Text(a *storage.ArticleContent) string {
+	if a.ContentText != "" {
+		return a.ContentText
+	}
+	return content.PlainText(a.ContentHTML)
+}
@@ -0,0 +1,366 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore mirrors SaveArticleContent's keep-on-failure semantics.
+type memStore struct {
+	mu   sync.Mutex
+	rows map[int64]storage.ArticleContent
+	now  func() time.Time
+}
+
+func (m *memStore) GetArticleContent(_ context.Context, storyID int64) (*storage.ArticleContent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.rows[storyID]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *memStore) SaveArticleContent(_ context.Context, a *storage.ArticleContent) (*storage.ArticleContent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	row := *a
+	if prev, ok := m.rows[a.StoryID]; ok && a.Status != storage.ArticleStatusOK && prev.URL == a.URL {
+		row.Title, row.ContentHTML, row.ContentText, row.CanIframe = prev.Title, prev.ContentHTML, prev.ContentText, prev.CanIframe
+		row.Source, row.ArchiveURL = prev.Source, prev.ArchiveURL
+		if a.Preview.IsZero() {
+			row.Preview = prev.Preview
+		}
+	}
+	if prev, ok := m.rows[a.StoryID]; ok && prev.URL == a.URL {
+		row.ArchiveMissedAt = prev.ArchiveMissedAt
+	}
+	row.FetchedAt = m.now()
+	m.rows[a.StoryID] = row
+	return &row, nil
+}
+
+func (m *memStore) RecordArchiveMiss(_ context.Context, storyID int64, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if row, ok := m.rows[storyID]; ok && row.URL == url {
+		now := m.now()
+		row.ArchiveMissedAt = &now
+		m.rows[storyID] = row
+	}
+	return nil
+}
+
+type fixture struct {
+	cache   *Cache
+	clock   time.Time
+	fetches int
+	result  *content.FetchResult
+	err     error
+}
+
+func newFixture() *fixture {
+	f := &fixture{clock: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	now := func() time.Time { return f.clock }
+	store := &memStore{rows: map[int64]storage.ArticleContent{}, now: now}
+	f.cache = New(store, f)
+	f.cache.now = now
+	return f
+}
+
+func (f *fixture) Fetch(context.Context, string, content.Options) (*content.FetchResult, error) {
+	f.fetches++
+	return f.result, f.err
+}
+
+func TestCache_FetchesOncePerTTL(t *testing.T) {
+	f := newFixture()
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", Text: "Hello", Title: "Hi", StatusCode: 200}
+
+	a, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", a.ContentText)
+	assert.Equal(t, 200, *a.HTTPStatus)
+
+	f.clock = f.clock.Add(time.Hour)
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, f.fetches)
+
+	f.clock = f.clock.Add(DefaultTTL)
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, f.fetches)
+}
+
+func TestCache_FailedRefreshKeepsContent(t *testing.T) {
+	f := newFixture()
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200, Metadata: content.Metadata{
+		Description: "A greeting",
+		Favicon:     "https://example.com/favicon.ico",
+	}}
+	a, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "A greeting", *a.Preview.Description)
+	assert.Nil(t, a.Preview.ImageURL)
+
+	f.clock = f.clock.Add(DefaultTTL)
+	f.result = &content.FetchResult{Content: "Not Found", StatusCode: 404, Outcome: content.OutcomeError}
+	a, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, storage.ArticleStatusFailed, a.Status)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Equal(t, "https://example.com/favicon.ico", *a.Preview.FaviconURL)
+	assert.Equal(t, 404, *a.HTTPStatus)
+	assert.Equal(t, string(content.OutcomeError), a.Outcome)
+
+	// Failures are retried after the shorter failure TTL
+	f.clock = f.clock.Add(DefaultFailureTTL / 2)
+	_, _ = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.Equal(t, 2, f.fetches)
+	f.clock = f.clock.Add(DefaultFailureTTL)
+	_, _ = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.Equal(t, 3, f.fetches)
+}
+
+func TestCache_UnavailableWithoutContent(t *testing.T) {
+	f := newFixture()
+	f.err = errors.New("connection refused")
+
+	_, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	// The failure is cached too
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, 1, f.fetches)
+}
+
+func TestCache_WallsAreNotContent(t *testing.T) {
+	f := newFixture()
+	f.result = &content.FetchResult{
+		Content: "<p>Subscribe to continue reading</p>", StatusCode: 200, Outcome: content.OutcomePaywall,
+		Metadata: content.Metadata{Description: "Why margins are collapsing"},
+	}
+
+	_, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, content.OutcomePaywall, blocked.Outcome)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	// The teaser's preview is kept; its text is not
+	row, err := f.cache.store.GetArticleContent(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ArticleStatusFailed, row.Status)
+	assert.Empty(t, row.ContentHTML)
+	assert.Equal(t, "Why margins are collapsing", *row.Preview.Description)
+
+	// A bot check later doesn't replace an earlier extract
+	f.clock = f.clock.Add(DefaultFailureTTL)
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200, Outcome: content.OutcomeArticle}
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	require.NoError(t, err)
+	f.clock = f.clock.Add(DefaultTTL)
+	f.result = &content.FetchResult{Content: "<p>Just a moment</p>", StatusCode: 200, Outcome: content.OutcomeCaptcha}
+	a, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Equal(t, string(content.OutcomeCaptcha), a.Outcome)
+}
+
+func TestCache_PaywallPreviewReplacesEarlierFailure(t *testing.T) {
+	f := newFixture()
+	f.err = errors.New("connection refused")
+	_, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	f.clock = f.clock.Add(DefaultFailureTTL)
+	f.err = nil
+	f.result = &content.FetchResult{
+		Content: "<p>Subscribe to continue reading</p>", StatusCode: 200, Outcome: content.OutcomePaywall,
+		Metadata: content.Metadata{Description: "Why margins are collapsing"},
+	}
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	row, err := f.cache.store.GetArticleContent(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Why margins are collapsing", *row.Preview.Description)
+	assert.Equal(t, string(content.OutcomePaywall), row.Outcome)
+}
+
+func TestCache_RefetchesWhenURLChanges(t *testing.T) {
+	f := newFixture()
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200}
+	_, _ = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	_, _ = f.cache.Get(context.Background(), 1, "https://example.com/b")
+	assert.Equal(t, 2, f.fetches)
+}
+
+func TestCache_CancellationIsNotRecorded(t *testing.T) {
+	f := newFixture()
+	f.err = context.Canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := f.cache.Get(ctx, 1, "https://example.com/a")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	f.err = nil
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200}
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, f.fetches)
+}
+
+type archive struct {
+	fetches int
+	result  *content.FetchResult
+	err     error
+}
+
+func (a *archive) Fetch(context.Context, string) (*content.FetchResult, error) {
+	a.fetches++
+	return a.result, a.err
+}
+
+func TestResolve_FallsBackToArchive(t *testing.T) {
+	f := newFixture()
+	arch := &archive{result: &content.FetchResult{
+		Content: "<p>Archived</p>", StatusCode: 200,
+		URL: "https://web.archive.org/web/20240101000000/https://example.com/a",
+	}}
+	f.cache.Archive = arch
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200}
+
+	// A healthy origin is used directly
+	a, src, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceOrigin, src)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+
+	// Once the origin fails, the snapshot replaces our copy
+	f.clock = f.clock.Add(DefaultTTL)
+	f.result = &content.FetchResult{StatusCode: 404}
+	a, src, err = f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceArchive, src)
+	assert.Equal(t, "<p>Archived</p>", a.ContentHTML)
+	assert.Equal(t, arch.result.URL, *a.ArchiveURL)
+	assert.False(t, a.CanIframe)
+
+	// and is cached: neither the origin nor the archive is asked again
+	a, src, err = f.cache.Resolve(context.Background(), 1, "https://example.com/a", true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceArchive, src)
+	a, src, err = f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceArchive, src)
+	assert.Equal(t, 2, f.fetches)
+	assert.Equal(t, 1, arch.fetches)
+}
+
+func TestResolve_DeadLinkWithoutSnapshotTriesOrigin(t *testing.T) {
+	f := newFixture()
+	arch := &archive{err: content.ErrNoSnapshot}
+	f.cache.Archive = arch
+	f.result = &content.FetchResult{Content: "<p>Hello</p>", StatusCode: 200}
+	_, err := f.cache.Get(context.Background(), 1, "https://example.com/a")
+	require.NoError(t, err)
+
+	// The link check may be wrong: a page that still serves is used
+	f.clock = f.clock.Add(DefaultTTL)
+	a, src, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceOrigin, src)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Equal(t, 2, f.fetches)
+	assert.Equal(t, 1, arch.fetches)
+
+	// Once the origin fails too, our own extract is the last resort
+	f.clock = f.clock.Add(DefaultTTL)
+	f.result = &content.FetchResult{StatusCode: 404}
+	a, src, err = f.cache.Resolve(context.Background(), 1, "https://example.com/a", true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceCache, src)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Equal(t, 3, f.fetches)
+
+	_, _, err = f.cache.Resolve(context.Background(), 2, "https://example.com/b", true)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestResolve_ArchiveMissesAreCached(t *testing.T) {
+	f := newFixture()
+	arch := &archive{err: content.ErrNoSnapshot}
+	f.cache.Archive = arch
+	f.result = &content.FetchResult{StatusCode: 503, Outcome: content.OutcomeError}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	assert.Equal(t, 1, f.fetches)
+	assert.Equal(t, 1, arch.fetches)
+
+	// Dead links record the miss too, though they ask the archive first
+	for i := 0; i < 2; i++ {
+		_, _, err := f.cache.Resolve(context.Background(), 2, "https://example.com/b", true)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	assert.Equal(t, 2, arch.fetches)
+
+	// Both are asked again once the failure has expired
+	f.clock = f.clock.Add(DefaultFailureTTL)
+	_, _, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, 3, f.fetches)
+	assert.Equal(t, 3, arch.fetches)
+}
+
+func TestResolve_WallIsNotReplacedByArchive(t *testing.T) {
+	f := newFixture()
+	f.result = &content.FetchResult{StatusCode: 200, Content: "<p>Subscribe to continue reading</p>", Outcome: content.OutcomePaywall}
+	arch := &archive{result: &content.FetchResult{
+		Content: "<p>Archived</p>", StatusCode: 200,
+		URL: "https://web.archive.org/web/20240101000000/https://example.com/a",
+	}}
+	f.cache.Archive = arch
+
+	// The caller learns what was in the way, even with a snapshot available
+	_, _, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, content.OutcomePaywall, blocked.Outcome)
+	assert.Zero(t, arch.fetches)
+
+	// Nothing usable is cached for summaries to pick up
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	require.ErrorAs(t, err, &blocked)
+	row, err := f.cache.store.GetArticleContent(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ArticleStatusFailed, row.Status)
+
+	// An earlier extract of our own is still served
+	f.clock = f.clock.Add(DefaultFailureTTL)
+	f.result = &content.FetchResult{StatusCode: 200, Content: "<p>Hello</p>", Outcome: content.OutcomeArticle}
+	_, err = f.cache.Get(context.Background(), 1, "https://example.com/a")
+	require.NoError(t, err)
+	f.clock = f.clock.Add(DefaultTTL)
+	f.result = &content.FetchResult{StatusCode: 200, Content: "<p>Sign in</p>", Outcome: content.OutcomeLoginWall}
+	a, src, err := f.cache.Resolve(context.Background(), 1, "https://example.com/a", false)
+	require.NoError(t, err)
+	assert.Equal(t, SourceCache, src)
+	assert.Equal(t, "<p>Hello</p>", a.ContentHTML)
+	assert.Zero(t, arch.fetches)
+}
@@ -0,0 +1,190 @@
+// Package bookmarks reads and writes the Netscape bookmark file format that
+// browsers and read-later services use for import/export.
+package bookmarks
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Bookmark is a single link. Folder is the innermost enclosing folder name.
+type Bookmark struct {
+	URL      string
+	Title    string
+	Folder   string
+	Tags     []string
+	Note     string
+	AddedAt  time.Time
+	HNItemID int // HN_ITEM_ID attribute written by HN Station exports; 0 if absent
+}
+
+// Parse extracts bookmarks from a Netscape bookmark file. It is lenient:
+// the format is loosely specified and real exports are rarely well formed.
+func Parse(r io.Reader) ([]Bookmark, error) {
+	z := nethtml.NewTokenizer(r)
+
+	var out []Bookmark
+	var folders []string     // stack of enclosing folder names
+	var pendingFolder string // H3 text waiting for its <DL>
+	var inH3, inA, inDD bool
+	var text strings.Builder
+	var current *Bookmark
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case nethtml.ErrorToken:
+			if z.Err() == io.EOF {
+				return out, nil
+			}
+			return out, z.Err()
+
+		case nethtml.StartTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.H3:
+				inH3 = true
+				text.Reset()
+			case atom.Dl:
+				folders = append(folders, pendingFolder)
+				pendingFolder = ""
+			case atom.A:
+				b := Bookmark{}
+				for _, attr := range tok.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "href":
+						b.URL = strings.TrimSpace(attr.Val)
+					case "tags":
+						b.Tags = splitTags(attr.Val)
+					case "add_date":
+						if secs, err := strconv.ParseInt(attr.Val, 10, 64); err == nil {
+							b.AddedAt = time.Unix(secs, 0).UTC()
+						}
+					case "hn_item_id":
+						if id, err := strconv.Atoi(attr.Val); err == nil && id > 0 {
+							b.HNItemID = id
+						}
+					}
+				}
+				if len(folders) > 0 {
+					b.Folder = folders[len(folders)-1]
+				}
+				current = &b
+				inA = true
+				text.Reset()
+			case atom.Dd:
+				inDD = true
+				text.Reset()
+			case atom.Dt:
+				inDD = flushNote(&out, inDD, &text)
+			}
+
+		case nethtml.EndTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.H3:
+				inH3 = false
+				pendingFolder = strings.TrimSpace(text.String())
+			case atom.A:
+				if current != nil && current.URL != "" {
+					current.Title = strings.TrimSpace(text.String())
+					out = append(out, *current)
+				}
+				current = nil
+				inA = false
+			case atom.Dl:
+				inDD = flushNote(&out, inDD, &text)
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			}
+
+		case nethtml.TextToken:
+			if inH3 || inA || inDD {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// flushNote attaches a pending <DD> description to the last bookmark.
+// <DD> has no closing tag, so it ends at the next <DT> or </DL>.
+func flushNote(out *[]Bookmark, inDD bool, text *strings.Builder) bool {
+	if inDD && len(*out) > 0 {
+		(*out)[len(*out)-1].Note = strings.TrimSpace(text.String())
+	}
+	text.Reset()
+	return false
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Write renders bookmarks as a Netscape bookmark file, grouping them by
+// folder in the order folders first appear. Bookmarks without a folder are
+// written at the top level.
+func Write(w io.Writer, title string, items []Bookmark) error {
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	sb.WriteString("<!-- This is an automatically generated file. -->\n")
+	sb.WriteString(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">` + "\n")
+	fmt.Fprintf(&sb, "<TITLE>%s</TITLE>\n<H1>%s</H1>\n<DL><p>\n", html.EscapeString(title), html.EscapeString(title))
+
+	var order []string
+	byFolder := make(map[string][]Bookmark)
+	for _, b := range items {
+		if _, ok := byFolder[b.Folder]; !ok {
+			order = append(order, b.Folder)
+		}
+		byFolder[b.Folder] = append(byFolder[b.Folder], b)
+	}
+
+	for _, folder := range order {
+		indent := "    "
+		if folder != "" {
+			fmt.Fprintf(&sb, "    <DT><H3>%s</H3>\n    <DL><p>\n", html.EscapeString(folder))
+			indent = "        "
+		}
+		for _, b := range byFolder[folder] {
+			writeBookmark(&sb, indent, b)
+		}
+		if folder != "" {
+			sb.WriteString("    </DL><p>\n")
+		}
+	}
+
+	sb.WriteString("</DL><p>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeBookmark(sb *strings.Builder, indent string, b Bookmark) {
+	fmt.Fprintf(sb, `%s<DT><A HREF="%s"`, indent, html.EscapeString(b.URL))
+	if !b.AddedAt.IsZero() {
+		fmt.Fprintf(sb, ` ADD_DATE="%d"`, b.AddedAt.Unix())
+	}
+	if len(b.Tags) > 0 {
+		fmt.Fprintf(sb, ` TAGS="%s"`, html.EscapeString(strings.Join(b.Tags, ",")))
+	}
+	if b.HNItemID > 0 {
+		fmt.Fprintf(sb, ` HN_ITEM_ID="%d"`, b.HNItemID)
+	}
+	fmt.Fprintf(sb, ">%s</A>\n", html.EscapeString(b.Title))
+	if b.Note != "" {
+		fmt.Fprintf(sb, "%s<DD>%s\n", indent, html.EscapeString(b.Note))
+	}
+}
@@ -0,0 +1,65 @@
+package bookmarks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const firefoxExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks Menu</H1>
+<DL><p>
+    <DT><A HREF="https://news.ycombinator.com/item?id=1" ADD_DATE="1700000000">Top level</A>
+    <DT><H3 ADD_DATE="1700000000">Reading</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/a" TAGS="go,databases">Post &amp; Essay</A>
+        <DD>Read the comments
+        <DT><H3>Nested</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/b">Nested link</A>
+        </DL><p>
+        <DT><A HREF="https://example.com/c">After nested</A>
+    </DL><p>
+</DL><p>
+`
+
+func TestParse(t *testing.T) {
+	items, err := Parse(strings.NewReader(firefoxExport))
+	assert.NoError(t, err)
+	assert.Len(t, items, 4)
+
+	assert.Equal(t, "https://news.ycombinator.com/item?id=1", items[0].URL)
+	assert.Equal(t, "", items[0].Folder)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), items[0].AddedAt)
+
+	assert.Equal(t, "Post & Essay", items[1].Title)
+	assert.Equal(t, "Reading", items[1].Folder)
+	assert.Equal(t, []string{"go", "databases"}, items[1].Tags)
+	assert.Equal(t, "Read the comments", items[1].Note)
+
+	assert.Equal(t, "Nested", items[2].Folder)
+	assert.Equal(t, "Reading", items[3].Folder)
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	in := []Bookmark{
+		{URL: "https://example.com/a?x=1&y=2", Title: "A <b>", Folder: "Work", Tags: []string{"go"}, Note: "note", AddedAt: time.Unix(1700000000, 0).UTC(), HNItemID: 8863},
+		{URL: "https://example.com/b", Title: "B"},
+		{URL: "https://example.com/c", Title: "C", Folder: "Work"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, "HN Station", in))
+
+	out, err := Parse(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, out, 3)
+	assert.Equal(t, in[0], out[0])
+	assert.Equal(t, "Work", out[1].Folder)
+	assert.Equal(t, "", out[2].Folder)
+}
@@ -0,0 +1,170 @@
+// Package cache holds encoded API responses for anonymous requests. Entries
+// belong to a data version (the ingest run counter) and are dropped when the
+// version changes, with a TTL as a fallback for missed version updates.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response body and its strong ETag.
+type Entry struct {
+	Body []byte
+	ETag string
+}
+
+type entry struct {
+	Entry
+	storedAt time.Time
+}
+
+// Counters are the hit/miss counts for one namespace.
+type Counters struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// Stats is a snapshot of the cache's state and metrics.
+type Stats struct {
+	Version    int64               `json:"version"`
+	Entries    int                 `json:"entries"`
+	Namespaces map[string]Counters `json:"namespaces"`
+}
+
+// Cache is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	entries    map[string]entry
+	version    int64
+	ttl        time.Duration
+	maxEntries int
+	counters   map[string]*Counters
+	now        func() time.Time
+}
+
+// New creates a cache holding up to maxEntries responses for at most ttl each.
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		entries:    make(map[string]entry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		counters:   make(map[string]*Counters),
+		now:        time.Now,
+	}
+}
+
+// ETag returns a strong entity tag for body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func cacheKey(namespace, key string) string {
+	return namespace + "\x00" + key
+}
+
+func (c *Cache) counter(namespace string) *Counters {
+	ctr, ok := c.counters[namespace]
+	if !ok {
+		ctr = &Counters{}
+		c.counters[namespace] = ctr
+	}
+	return ctr
+}
+
+// Get returns the cached entry for key, recording a hit or miss.
+func (c *Cache) Get(namespace, key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[cacheKey(namespace, key)]
+	if ok && c.now().Sub(e.storedAt) > c.ttl {
+		delete(c.entries, cacheKey(namespace, key))
+		ok = false
+	}
+
+	if ok {
+		c.counter(namespace).Hits++
+		return e.Entry, true
+	}
+	c.counter(namespace).Misses++
+	return Entry{}, false
+}
+
+// Set stores body if version is still current, so a response built from data
+// older than a concurrent version change is not cached. Returns the entry
+// either way.
+func (c *Cache) Set(namespace, key string, body []byte, version int64) Entry {
+	e := Entry{Body: body, ETag: ETag(body)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		return e
+	}
+	if len(c.entries) >= c.maxEntries {
+		// Evict an arbitrary entry; map iteration order is random
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[cacheKey(namespace, key)] = entry{Entry: e, storedAt: c.now()}
+	return e
+}
+
+// Version returns the current data version. Read it before loading data to cache.
+func (c *Cache) Version() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// SetVersion moves the cache to a new data version, dropping all entries if it changed.
+func (c *Cache) SetVersion(version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version == c.version {
+		return
+	}
+	c.version = version
+	c.entries = make(map[string]entry)
+}
+
+// Invalidate drops a single entry, e.g. after a write on this replica.
+func (c *Cache) Invalidate(namespace, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, cacheKey(namespace, key))
+}
+
+// InvalidateNamespace drops every entry in a namespace, for writes that
+// affect responses under many keys.
+func (c *Cache) InvalidateNamespace(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := cacheKey(namespace, "")
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Stats returns the current version, entry count and per-namespace counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{Version: c.version, Entries: len(c.entries), Namespaces: make(map[string]Counters, len(c.counters))}
+	for ns, ctr := range c.counters {
+		stats.Namespaces[ns] = *ctr
+	}
+	return stats
+}
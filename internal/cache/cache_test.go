@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetSet(t *testing.T) {
+	c := New(time.Minute, 10)
+
+	_, ok := c.Get("stories", "a")
+	assert.False(t, ok)
+
+	stored := c.Set("stories", "a", []byte(`[1]`), c.Version())
+	got, ok := c.Get("stories", "a")
+	assert.True(t, ok)
+	assert.Equal(t, stored, got)
+	assert.Equal(t, ETag([]byte(`[1]`)), got.ETag)
+
+	stats := c.Stats()
+	assert.Equal(t, Counters{Hits: 1, Misses: 1}, stats.Namespaces["stories"])
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestCache_VersionChangeClears(t *testing.T) {
+	c := New(time.Minute, 10)
+	v := c.Version()
+	c.Set("story", "1", []byte(`{}`), v)
+
+	c.SetVersion(v + 1)
+	_, ok := c.Get("story", "1")
+	assert.False(t, ok)
+
+	// Responses built against the old version are not stored
+	c.Set("story", "1", []byte(`{}`), v)
+	_, ok = c.Get("story", "1")
+	assert.False(t, ok)
+}
+
+func TestCache_InvalidateNamespace(t *testing.T) {
+	c := New(time.Minute, 10)
+	c.Set("stories", "a", []byte(`[1]`), 0)
+	c.Set("stories", "b", []byte(`[2]`), 0)
+	c.Set("story", "1", []byte(`{}`), 0)
+
+	c.InvalidateNamespace("stories")
+	_, ok := c.Get("stories", "a")
+	assert.False(t, ok)
+	_, ok = c.Get("stories", "b")
+	assert.False(t, ok)
+	_, ok = c.Get("story", "1")
+	assert.True(t, ok)
+}
+
+func TestCache_TTLAndCapacity(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.Set("s", "a", []byte("a"), 0)
+	c.Set("s", "b", []byte("b"), 0)
+	c.Set("s", "c", []byte("c"), 0)
+	assert.Equal(t, 2, c.Stats().Entries)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("s", "c")
+	assert.False(t, ok)
+}
+
+func TestETag_IsStrongAndStable(t *testing.T) {
+	a := ETag([]byte("hello"))
+	assert.Equal(t, a, ETag([]byte("hello")))
+	assert.NotEqual(t, a, ETag([]byte("hello!")))
+	assert.Equal(t, byte('"'), a[0])
+}
@@ -0,0 +1,91 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultArchiveAPI = "https://archive.org"
+
+// ErrNoSnapshot is returned when the archive has no usable copy of a URL.
+var ErrNoSnapshot = errors.New("no archived snapshot")
+
+// Archive finds and extracts archived copies of pages through a Wayback
+// Machine style availability API: GET {APIBase}/wayback/available?url=...
+type Archive struct {
+	APIBase string
+	Fetcher *Fetcher
+}
+
+// ArchiveFromEnv configures the archive from ARCHIVE_API_URL, which points
+// at a local stand-in for the availability API. The stand-in is trusted by
+// f, so it may listen on a private address or any port.
+func ArchiveFromEnv(getenv func(string) string, f *Fetcher) *Archive {
+	a := &Archive{APIBase: DefaultArchiveAPI, Fetcher: f}
+	if base := strings.TrimRight(getenv("ARCHIVE_API_URL"), "/"); base != "" {
+		a.APIBase = base
+		f.Trust(base)
+	}
+	return a
+}
+
+// Snapshot is an archived copy of a page.
+type Snapshot struct {
+	URL        string
+	CapturedAt time.Time
+}
+
+type waybackAvailability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Lookup returns the most recent successful snapshot of a URL.
+func (a *Archive) Lookup(ctx context.Context, rawURL string) (*Snapshot, error) {
+	var avail waybackAvailability
+	if err := a.Fetcher.getJSON(ctx, a.APIBase+"/wayback/available?url="+url.QueryEscape(rawURL), nil, &avail); err != nil {
+		return nil, err
+	}
+	closest := avail.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" || closest.Status != "200" {
+		return nil, ErrNoSnapshot
+	}
+
+	snapshot := &Snapshot{URL: closest.URL}
+	if t, err := time.Parse("20060102150405", closest.Timestamp); err == nil {
+		snapshot.CapturedAt = t
+	}
+	return snapshot, nil
+}
+
+// Fetch extracts the most recent snapshot of a URL. The result's URL is the
+// snapshot's.
+func (a *Archive) Fetch(ctx context.Context, rawURL string) (*FetchResult, error) {
+	snapshot, err := a.Lookup(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.Fetcher.Fetch(ctx, snapshot.URL, Options{})
+	if err != nil {
+		return nil, err
+	}
+	if result.StatusCode >= 400 {
+		return nil, fmt.Errorf("archived snapshot: HTTP %d", result.StatusCode)
+	}
+	if result.Outcome.Blocked() {
+		// The crawler saw the same wall we did
+		return nil, fmt.Errorf("archived snapshot: %s", result.Outcome)
+	}
+	result.URL = snapshot.URL
+	return result, nil
+}
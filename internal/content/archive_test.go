@@ -0,0 +1,104 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_FetchesLatestSnapshot(t *testing.T) {
+	page := fixture(t, "article.html")
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Host == "archive.test" && r.URL.RequestURI() == "/wayback/available?url=https%3A%2F%2Fexample.com%2Fblog%2Fpostgres-17":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(fixture(t, "extractors/wayback_available.json"))
+		case r.Host == "web.archive.org":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	env := map[string]string{"ARCHIVE_API_URL": "http://archive.test/"}
+	archive := ArchiveFromEnv(func(key string) string { return env[key] }, f)
+
+	snapshot, err := archive.Lookup(context.Background(), "https://example.com/blog/postgres-17")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 9, 26, 12, 0, 0, 0, time.UTC), snapshot.CapturedAt)
+
+	result, err := archive.Fetch(context.Background(), "https://example.com/blog/postgres-17")
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres 17 Released", result.Title)
+	assert.Equal(t, "http://web.archive.org/web/20240926120000/https://example.com/blog/postgres-17", result.URL)
+
+	_, err = archive.Fetch(context.Background(), "https://example.com/never-archived")
+	assert.Error(t, err)
+	assert.Zero(t, tracker.open.Load())
+}
+
+func TestArchive_NoSnapshot(t *testing.T) {
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"url": "example.com/gone", "archived_snapshots": {}}`))
+	}))
+
+	_, err := (&Archive{APIBase: DefaultArchiveAPI, Fetcher: f}).Lookup(context.Background(), "https://example.com/gone")
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestArchiveFromEnv_LocalStandIn(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture(t, "extractors/wayback_available.json"))
+	}))
+	defer ts.Close()
+
+	// The SSRF-safe fetcher refuses loopback, except for the configured API
+	env := map[string]string{"ARCHIVE_API_URL": ts.URL + "/"}
+	archive := ArchiveFromEnv(func(key string) string { return env[key] }, NewFetcher(nil))
+	snapshot, err := archive.Lookup(context.Background(), "https://example.com/blog/postgres-17")
+	require.NoError(t, err)
+	assert.Contains(t, snapshot.URL, "web.archive.org")
+}
+
+func TestCheck_FallsBackToGet(t *testing.T) {
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Write([]byte("ok"))
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("ok"))
+		case "/moved":
+			http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+		default:
+			http.Error(w, "gone", http.StatusGone)
+		}
+	}))
+
+	status, err := f.Check(context.Background(), "http://example.com/no-head")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, err = f.Check(context.Background(), "http://example.com/get-only")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, err = f.Check(context.Background(), "http://example.com/moved")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGone, status)
+	assert.Zero(t, tracker.open.Load())
+}
@@ -0,0 +1,94 @@
+package content
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const DefaultArxivAPI = "https://export.arxiv.org/api"
+
+// arxivPath matches /abs/, /pdf/ and /html/ pages for new-style
+// (2401.01234v2) and old-style (hep-th/9901001) identifiers.
+var arxivPath = regexp.MustCompile(`^/(?:abs|pdf|html)/((?:\d{4}\.\d{4,5}|[a-z-]+(?:\.[A-Z]{2})?/\d{7})(?:v\d+)?)(?:\.pdf)?/?$`)
+
+// ArxivExtractor returns the title, authors and abstract of arXiv papers
+// from the arXiv API, including for links to the PDF.
+type ArxivExtractor struct {
+	APIBase string
+}
+
+func (e *ArxivExtractor) Name() string { return "arxiv" }
+
+type arxivFeed struct {
+	Entries []struct {
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Summary   string `xml:"summary"`
+		Published string `xml:"published"`
+		Authors   []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+	} `xml:"entry"`
+}
+
+func (e *ArxivExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	m := arxivPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return nil, ErrNotHandled
+	}
+	id := m[1]
+
+	resp, err := f.Get(ctx, e.APIBase+"/query?id_list="+url.QueryEscape(id), Options{
+		AcceptTypes: []string{"application/atom+xml", "application/xml", "text/xml"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("arXiv API: HTTP %d", resp.StatusCode)
+	}
+
+	var feed arxivFeed
+	if err := xml.Unmarshal(resp.Body, &feed); err != nil {
+		return nil, err
+	}
+	// Unknown identifiers come back as an entry pointing at api/errors
+	if len(feed.Entries) == 0 || strings.Contains(feed.Entries[0].ID, "/api/errors") {
+		return nil, errors.New("arXiv API: paper not found")
+	}
+	entry := feed.Entries[0]
+
+	title := collapseSpace(strings.TrimSpace(entry.Title))
+	authors := make([]string, 0, len(entry.Authors))
+	for _, a := range entry.Authors {
+		authors = append(authors, strings.TrimSpace(a.Name))
+	}
+
+	var b strings.Builder
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>")
+	if len(authors) > 0 {
+		b.WriteString("<p>" + html.EscapeString(strings.Join(authors, ", ")) + "</p>")
+	}
+	if published, _, ok := strings.Cut(entry.Published, "T"); ok {
+		b.WriteString("<p>Submitted " + html.EscapeString(published) + "</p>")
+	}
+	b.WriteString("<h2>Abstract</h2>")
+	b.WriteString("<p>" + html.EscapeString(collapseSpace(strings.TrimSpace(entry.Summary))) + "</p>")
+	b.WriteString(`<p><a href="https://arxiv.org/pdf/` + html.EscapeString(id) + `">PDF</a></p>`)
+
+	result := htmlResult(title, b.String(), "https://arxiv.org/abs/"+id)
+	result.Metadata = Metadata{
+		Description: truncateWords(entry.Summary, maxDescriptionLen),
+		SiteName:    "arXiv",
+		Author:      strings.Join(authors, ", "),
+		PublishedAt: parseMetaTime(entry.Published),
+	}
+	return result, nil
+}
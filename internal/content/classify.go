@@ -0,0 +1,224 @@
+package content
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Outcome says whether a fetch produced the article or a page standing in
+// front of it.
+type Outcome string
+
+const (
+	OutcomeArticle   Outcome = "article"
+	OutcomePaywall   Outcome = "paywall"
+	OutcomeLoginWall Outcome = "login_wall"
+	OutcomeCaptcha   Outcome = "captcha" // Bot challenges such as Cloudflare's
+	OutcomeConsent   Outcome = "consent" // Cookie and privacy consent walls
+	OutcomeError     Outcome = "error"   // An error status that is not a wall
+)
+
+// Blocked reports whether the content is a wall rather than the article.
+func (o Outcome) Blocked() bool {
+	return o != "" && o != OutcomeArticle && o != OutcomeError
+}
+
+// Describe is a short user-facing explanation of a blocked outcome.
+func (o Outcome) Describe() string {
+	switch o {
+	case OutcomePaywall:
+		return "The article is paywalled."
+	case OutcomeLoginWall:
+		return "The article requires signing in."
+	case OutcomeCaptcha:
+		return "The site answered with a bot check instead of the article."
+	case OutcomeConsent:
+		return "The site answered with a cookie consent page instead of the article."
+	default:
+		return ""
+	}
+}
+
+// Walls are short. Pages longer than wallMaxWords are taken to be the
+// article even when they mention subscriptions or signing in; interstitials
+// (bot checks, consent pages) are recognised only below interstitialMaxWords,
+// since ordinary pages embed captchas and cookie banners too.
+const (
+	wallMaxWords         = 400
+	interstitialMaxWords = 150
+)
+
+// siteRule marks sites whose articles are known to sit behind a wall.
+// Extracts from them shorter than minWords are the teaser, not the article.
+type siteRule struct {
+	outcome  Outcome
+	minWords int
+}
+
+var siteRules = map[string]siteRule{
+	"wsj.com":             {OutcomePaywall, 350},
+	"ft.com":              {OutcomePaywall, 350},
+	"economist.com":       {OutcomePaywall, 300},
+	"bloomberg.com":       {OutcomePaywall, 300},
+	"nytimes.com":         {OutcomePaywall, 250},
+	"washingtonpost.com":  {OutcomePaywall, 250},
+	"theathletic.com":     {OutcomePaywall, 300},
+	"newyorker.com":       {OutcomePaywall, 300},
+	"theatlantic.com":     {OutcomePaywall, 250},
+	"hbr.org":             {OutcomePaywall, 300},
+	"businessinsider.com": {OutcomePaywall, 250},
+	"thetimes.co.uk":      {OutcomePaywall, 250},
+	"barrons.com":         {OutcomePaywall, 300},
+	"linkedin.com":        {OutcomeLoginWall, 150},
+	"facebook.com":        {OutcomeLoginWall, 100},
+	"instagram.com":       {OutcomeLoginWall, 100},
+	"x.com":               {OutcomeLoginWall, 50},
+	"twitter.com":         {OutcomeLoginWall, 50},
+}
+
+// consentHosts serve consent interstitials that sites redirect to.
+var consentHosts = []string{"consent.google.com", "consent.youtube.com", "consent.yahoo.com", "guce.yahoo.com", "myprivacy.dpgmedia.net", "cmp.dpgmedia.nl"}
+
+var (
+	captchaMarkers = []string{
+		"cf-browser-verification", "challenge-platform", "cf_chl_opt", "cf-turnstile",
+		"g-recaptcha", "h-captcha", "px-captcha", "captcha-delivery.com", "geo.captcha-delivery.com",
+		"verify you are human", "verify you are a human", "are you a robot", "checking your browser",
+		"unusual traffic from your computer network", "enable javascript and cookies to continue",
+	}
+	captchaTitles = regexp.MustCompile(`(?i)^(just a moment\.*|attention required!.*|access denied|ddos-guard|security check|robot check|are you a robot\??)$`)
+
+	consentMarkers = []string{
+		"we use cookies", "uses cookies", "cookie consent", "cookie settings", "manage cookies",
+		"accept all cookies", "accept all", "reject all", "your privacy choices", "we value your privacy",
+	}
+	paywallMarkers = []string{
+		"subscribe to continue", "subscribe to read", "to continue reading", "subscribers only",
+		"for subscribers", "already a subscriber", "become a subscriber", "start your subscription",
+		"this article is exclusive to", "unlock this article", "member-only story", "create a free account to continue",
+	}
+	paywallClasses = regexp.MustCompile(`(?i)class="[^"]*\b(paywall|piano-?(?:offer|paywall)|tp-modal|meteredcontent|regwall|subscriber-only)\b`)
+	notFree        = regexp.MustCompile(`(?i)"isAccessibleForFree"\s*:\s*"?false"?`)
+	loginMarkers   = []string{
+		"sign in to continue", "log in to continue", "login to continue", "sign in to read",
+		"log in to read", "you must be logged in", "you need to log in", "please log in", "please sign in",
+		"join to view", "sign in to view",
+	}
+	loginPaths = regexp.MustCompile(`(?i)/(login|log-in|signin|sign-in|sign_in|authwall|auth/login|accounts/login|session/new)\b`)
+)
+
+// Classify decides whether a fetched page is the article or a wall, from the
+// response and the text extracted from it.
+func Classify(resp *Response, text string) Outcome {
+	if o := classifyStatus(resp.StatusCode, resp.Header); o != "" {
+		return o
+	}
+
+	u := resp.URL
+	rawHTML := string(resp.Body)
+	lowerHTML := strings.ToLower(rawHTML)
+	lowerText := strings.ToLower(collapseSpace(text))
+	words := WordCount(text)
+	short := words < wallMaxWords
+	tiny := words < interstitialMaxWords
+	host := ""
+	if u != nil {
+		host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+
+	switch {
+	case tiny && (captchaTitles.MatchString(htmlTitle(rawHTML)) || containsAny(lowerHTML, captchaMarkers)):
+		return OutcomeCaptcha
+	case hostIn(host, consentHosts) || (tiny && countAny(lowerText, consentMarkers) >= 2):
+		return OutcomeConsent
+	case u != nil && loginPaths.MatchString(u.Path) && short:
+		return OutcomeLoginWall
+	case short && containsAny(lowerText, loginMarkers) && strings.Contains(lowerHTML, `type="password"`):
+		return OutcomeLoginWall
+	case short && (notFree.MatchString(rawHTML) || paywallClasses.MatchString(rawHTML) || containsAny(lowerText, paywallMarkers)):
+		return OutcomePaywall
+	}
+
+	if rule, ok := lookupSiteRule(host); ok && words < rule.minWords {
+		return rule.outcome
+	}
+	return OutcomeArticle
+}
+
+// classifyStatus recognises walls from error responses, whose bodies are
+// not read. It returns "" when the headers show no wall.
+func classifyStatus(status int, header http.Header) Outcome {
+	if strings.EqualFold(header.Get("Cf-Mitigated"), "challenge") ||
+		strings.EqualFold(header.Get("X-Amzn-Waf-Action"), "captcha") ||
+		header.Get("X-Datadome") != "" && status == http.StatusForbidden {
+		return OutcomeCaptcha
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusProxyAuthRequired:
+		return OutcomeLoginWall
+	case http.StatusPaymentRequired:
+		return OutcomePaywall
+	case http.StatusForbidden, http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		server := strings.ToLower(header.Get("Server"))
+		if strings.Contains(server, "cloudflare") || strings.Contains(server, "ddos-guard") {
+			return OutcomeCaptcha
+		}
+	}
+	return ""
+}
+
+// classifyError is the outcome of an error response.
+func classifyError(resp *Response) Outcome {
+	if o := classifyStatus(resp.StatusCode, resp.Header); o != "" {
+		return o
+	}
+	return OutcomeError
+}
+
+// lookupSiteRule finds the rule for a host or any of its parent domains.
+func lookupSiteRule(host string) (siteRule, bool) {
+	for host != "" {
+		if rule, ok := siteRules[host]; ok {
+			return rule, true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return siteRule{}, false
+}
+
+func hostIn(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, markers []string) bool {
+	return countAny(s, markers) > 0
+}
+
+func countAny(s string, markers []string) int {
+	n := 0
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			n++
+		}
+	}
+	return n
+}
+
+var titleTag = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+func htmlTitle(rawHTML string) string {
+	if m := titleTag.FindStringSubmatch(rawHTML); m != nil {
+		return collapseSpace(m[1])
+	}
+	return ""
+}
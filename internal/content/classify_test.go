@@ -0,0 +1,77 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch_ClassifiesWalls(t *testing.T) {
+	pages := map[string][]byte{
+		"/article":    fixture(t, "article.html"),
+		"/cloudflare": fixture(t, "walls/cloudflare.html"),
+		"/consent":    fixture(t, "walls/consent.html"),
+		"/paywall":    fixture(t, "walls/paywall.html"),
+		"/thread":     fixture(t, "walls/login.html"),
+	}
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/challenged":
+			w.Header().Set("Cf-Mitigated", "challenge")
+			w.WriteHeader(http.StatusForbidden)
+		case "/payment":
+			w.WriteHeader(http.StatusPaymentRequired)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(pages[r.URL.Path])
+		}
+	}))
+
+	cases := map[string]Outcome{
+		"/article":    OutcomeArticle,
+		"/cloudflare": OutcomeCaptcha,
+		"/consent":    OutcomeConsent,
+		"/paywall":    OutcomePaywall,
+		"/thread":     OutcomeLoginWall,
+		"/challenged": OutcomeCaptcha,
+		"/payment":    OutcomePaywall,
+		"/missing":    OutcomeError,
+	}
+	for path, want := range cases {
+		result, err := f.Fetch(context.Background(), "http://example.com"+path, Options{})
+		require.NoError(t, err, path)
+		assert.Equal(t, want, result.Outcome, path)
+	}
+}
+
+func TestClassify_KnownSites(t *testing.T) {
+	resp := func(rawURL string) *Response {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		return &Response{URL: u, StatusCode: http.StatusOK, Body: []byte("<html><body></body></html>")}
+	}
+	teaser := strings.Repeat("word ", 120)
+	full := strings.Repeat("word ", 1200)
+
+	assert.Equal(t, OutcomePaywall, Classify(resp("https://www.nytimes.com/2026/01/01/tech/chips.html"), teaser))
+	assert.Equal(t, OutcomeArticle, Classify(resp("https://www.nytimes.com/2026/01/01/tech/chips.html"), full))
+	assert.Equal(t, OutcomePaywall, Classify(resp("https://markets.ft.com/data/story"), teaser))
+	assert.Equal(t, OutcomeLoginWall, Classify(resp("https://www.linkedin.com/pulse/some-post"), "Join to view"))
+	assert.Equal(t, OutcomeArticle, Classify(resp("https://blog.example.com/post"), teaser))
+	assert.Equal(t, OutcomeConsent, Classify(resp("https://consent.google.com/ml?continue=https://news.google.com"), teaser))
+}
+
+func TestClassify_LongArticlesMentioningWallsAreArticles(t *testing.T) {
+	u, _ := url.Parse("https://blog.example.com/captcha-design")
+	body := `<html><head><title>How we built our captcha</title></head><body><div class="g-recaptcha"></div></body></html>`
+	text := "Subscribe to continue reading our series. We use cookies. " + strings.Repeat("word ", 800)
+
+	assert.Equal(t, OutcomeArticle, Classify(&Response{URL: u, StatusCode: http.StatusOK, Body: []byte(body)}, text))
+}
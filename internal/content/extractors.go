@@ -0,0 +1,192 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrNotHandled is returned by an extractor for URLs it does not recognise.
+var ErrNotHandled = errors.New("url not handled by extractor")
+
+// Extractor produces article content for pages on specific sites, usually
+// through the site's API. Fetch falls back to generic extraction when an
+// extractor returns an error.
+type Extractor interface {
+	Name() string
+	Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error)
+}
+
+// Registry maps host patterns to extractors. A pattern is a host
+// ("github.com"), a subdomain wildcard ("*.example.com") or "*" for any
+// host. A leading "www." is ignored when matching.
+type Registry struct {
+	hosts     map[string][]Extractor
+	wildcards map[string][]Extractor // Keyed by suffix, including the leading dot
+	any       []Extractor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{hosts: make(map[string][]Extractor), wildcards: make(map[string][]Extractor)}
+}
+
+// Register adds an extractor for a host pattern.
+func (r *Registry) Register(pattern string, e Extractor) {
+	pattern = strings.ToLower(pattern)
+	switch {
+	case pattern == "*":
+		r.any = append(r.any, e)
+	case strings.HasPrefix(pattern, "*."):
+		suffix := pattern[1:]
+		r.wildcards[suffix] = append(r.wildcards[suffix], e)
+	default:
+		r.hosts[pattern] = append(r.hosts[pattern], e)
+	}
+}
+
+// Match returns the extractors for a host, most specific first.
+func (r *Registry) Match(host string) []Extractor {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	matched := append([]Extractor(nil), r.hosts[host]...)
+	for suffix, extractors := range r.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			matched = append(matched, extractors...)
+		}
+	}
+	return append(matched, r.any...)
+}
+
+// DefaultExtractors registers the built-in site extractors with the public
+// GitHub API.
+func DefaultExtractors() *Registry {
+	return NewExtractors(&GitHubExtractor{APIBase: DefaultGitHubAPI})
+}
+
+// NewExtractors registers the built-in site extractors, using github for
+// GitHub repositories, issues and gists.
+func NewExtractors(github *GitHubExtractor) *Registry {
+	r := NewRegistry()
+	r.Register("arxiv.org", &ArxivExtractor{APIBase: DefaultArxivAPI})
+	r.Register("github.com", github)
+	r.Register("gist.github.com", &GistExtractor{GitHub: github})
+	r.Register("gitlab.com", &GitLabExtractor{APIBase: DefaultGitLabAPI})
+	r.Register("codeberg.org", &GiteaExtractor{APIBase: DefaultCodebergAPI})
+	r.Register("*", &MastodonExtractor{}) // Instances live on any host
+	return r
+}
+
+// extract runs the matching extractors. It returns nil when none handled
+// the URL, so the caller falls back to generic extraction.
+func (f *Fetcher) extract(ctx context.Context, rawURL string) (*FetchResult, error) {
+	if f.Extractors == nil {
+		return nil, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, e := range f.Extractors.Match(u.Hostname()) {
+		result, err := e.Extract(ctx, f, u)
+		if err == nil {
+			result.Extractor = e.Name()
+			result.Outcome = OutcomeArticle // Extractors read APIs, not pages
+			if result.Metadata.Favicon == "" {
+				final, _ := url.Parse(result.URL)
+				result.Metadata.Favicon = defaultFavicon(final)
+			}
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ErrNotHandled) {
+			log.Printf("%s extractor failed for %s, falling back: %v", e.Name(), rawURL, err)
+		}
+	}
+	return nil, nil
+}
+
+// getJSON fetches an API endpoint and decodes a 200 response into v.
+func (f *Fetcher) getJSON(ctx context.Context, rawURL string, header http.Header, v interface{}) error {
+	resp, err := f.Get(ctx, rawURL, Options{AcceptTypes: []string{"application/json"}, Header: header})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return json.Unmarshal(resp.Body, v)
+}
+
+// htmlResult builds a FetchResult from generated HTML.
+func htmlResult(title, body, finalURL string) *FetchResult {
+	return &FetchResult{
+		Content:    body,
+		Text:       PlainText(body),
+		Title:      title,
+		CanIframe:  false,
+		StatusCode: http.StatusOK,
+		URL:        finalURL,
+	}
+}
+
+// pathParts splits a URL path into its non-empty segments.
+func pathParts(u *url.URL) []string {
+	var parts []string
+	for _, p := range strings.Split(u.Path, "/") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// readmeHTML renders a README: Markdown files are converted, anything else
+// is shown preformatted.
+func readmeHTML(name, body, linkBase, imageBase string) (string, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return RenderMarkdown(body, linkBase, imageBase)
+	default:
+		return "<pre>" + html.EscapeString(body) + "</pre>", nil
+	}
+}
+
+// repoPage lays out a repository description followed by its README.
+func repoPage(name, description, readme string) string {
+	var b strings.Builder
+	b.WriteString("<h1>" + html.EscapeString(name) + "</h1>")
+	if description != "" {
+		b.WriteString("<p>" + html.EscapeString(description) + "</p>")
+	}
+	b.WriteString(readme)
+	return b.String()
+}
+
+// repoTitle formats "owner/repo: description".
+func repoTitle(name, description string) string {
+	if description == "" {
+		return name
+	}
+	return name + ": " + description
+}
@@ -0,0 +1,207 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiFixtures serves recorded API responses keyed by host and request URI.
+func apiFixtures(t *testing.T, routes map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := routes[r.Host+r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch path.Ext(name) {
+		case ".json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		case ".xml":
+			w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.Write(fixture(t, "extractors/"+name))
+	})
+}
+
+func TestRegistry_Match(t *testing.T) {
+	a, b, c := &MastodonExtractor{}, &ArxivExtractor{}, &GitHubExtractor{}
+	r := NewRegistry()
+	r.Register("*", a)
+	r.Register("arxiv.org", b)
+	r.Register("*.github.io", c)
+
+	assert.Equal(t, []Extractor{b, a}, r.Match("www.arxiv.org"))
+	assert.Equal(t, []Extractor{c, a}, r.Match("user.github.io"))
+	assert.Equal(t, []Extractor{a}, r.Match("example.com"))
+}
+
+func TestExtract_Arxiv(t *testing.T) {
+	f, tracker := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"export.arxiv.org/api/query?id_list=2401.01234v2": "arxiv_query.xml",
+	}))
+
+	for _, link := range []string{"https://arxiv.org/abs/2401.01234v2", "https://arxiv.org/pdf/2401.01234v2.pdf"} {
+		result, err := f.Fetch(context.Background(), link, Options{})
+		require.NoError(t, err, link)
+		assert.Equal(t, "arxiv", result.Extractor)
+		assert.Equal(t, "Tiny Papers: Small Models, Narrow Tasks", result.Title)
+		assert.Equal(t, "https://arxiv.org/abs/2401.01234v2", result.URL)
+		assert.Contains(t, result.Content, "<p>Ada Lovelace, Alan Turing</p>")
+		assert.Contains(t, result.Content, "<p>Submitted 2024-01-02</p>")
+		assert.Contains(t, result.Text, "small models can match them on narrow tasks")
+	}
+	assert.Zero(t, tracker.open.Load())
+}
+
+func TestExtract_GitHubRepo(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"api.github.com/repos/rajeshkumarblr/hn_station":                 "github_repo.json",
+		"api.github.com/repos/rajeshkumarblr/hn_station/readme?ref=main": "github_readme.json",
+	}))
+
+	result, err := f.Fetch(context.Background(), "https://github.com/rajeshkumarblr/hn_station", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "github", result.Extractor)
+	assert.Equal(t, "rajeshkumarblr/hn_station: A fast Hacker News reader", result.Title)
+	assert.Equal(t, "https://github.com/rajeshkumarblr/hn_station", result.URL)
+	assert.Contains(t, result.Content, `<a href="https://news.ycombinator.com">Hacker News</a>`)
+	assert.Contains(t, result.Content, `<img src="https://raw.githubusercontent.com/rajeshkumarblr/hn_station/main/docs/screenshot.png" alt="Screenshot"/>`)
+	assert.Contains(t, result.Content, `<a href="https://github.com/rajeshkumarblr/hn_station/blob/main/docs/SETUP.md">setup guide</a>`)
+}
+
+func TestExtract_GitHubIssue(t *testing.T) {
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github.html+json", r.Header.Get("Accept"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		apiFixtures(t, map[string]string{
+			"api.github.com/repos/golang/go/issues/61405":                      "github_issue.json",
+			"api.github.com/repos/golang/go/issues/61405/comments?per_page=50": "github_issue_comments.json",
+		}).ServeHTTP(w, r)
+	}))
+	f.Extractors = NewRegistry()
+	f.Extractors.Register("github.com", &GitHubExtractor{APIBase: DefaultGitHubAPI, Token: "secret"})
+
+	result, err := f.Fetch(context.Background(), "https://github.com/golang/go/issues/61405#issuecomment-1", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "spec: add range over int, range over func · golang/go#61405", result.Title)
+	assert.Equal(t, "https://github.com/golang/go/issues/61405", result.URL)
+	assert.Contains(t, result.Content, "<p>Issue #61405 by rsc, closed, opened 2023-07-17</p>")
+	assert.Contains(t, result.Content, "<h3>ianlancetaylor on 2023-07-19</h3><p>This proposal has been <strong>accepted</strong>.</p>")
+}
+
+func TestExtract_Gist(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"api.github.com/gists/aa5a315d61ae9438b18d": "gist.json",
+	}))
+
+	result, err := f.Fetch(context.Background(), "https://gist.github.com/octocat/aa5a315d61ae9438b18d", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "gist", result.Extractor)
+	assert.Equal(t, "Hello world examples", result.Title)
+	assert.Contains(t, result.Content, "<h2>NOTES.md</h2><p>Run it with <code>ruby hello_world.rb</code>.</p>")
+	assert.Contains(t, result.Content, `<pre><code class="language-ruby">class HelloWorld`)
+	assert.Contains(t, result.Content, `puts &#34;Hello &lt;World&gt;&#34;`)
+}
+
+func TestExtract_GitLab(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"gitlab.com/api/v4/projects/gitlab-org%2Fgitlab":                              "gitlab_project.json",
+		"gitlab.com/api/v4/projects/278964/repository/files/README.md/raw?ref=master": "gitlab_readme.md",
+	}))
+
+	result, err := f.Fetch(context.Background(), "https://gitlab.com/gitlab-org/gitlab/-/tree/master", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab", result.Extractor)
+	assert.Equal(t, "gitlab-org/gitlab: GitLab is an open source end-to-end software development platform.", result.Title)
+	assert.Contains(t, result.Content, `<img src="https://gitlab.com/gitlab-org/gitlab/-/raw/master/doc/logo.png" alt="logo"/>`)
+	assert.Contains(t, result.Content, `<a href="https://gitlab.com/gitlab-org/gitlab/-/blob/master/CONTRIBUTING.md">contributing guide</a>`)
+}
+
+func TestExtract_Codeberg(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"codeberg.org/api/v1/repos/forgejo/forgejo":                      "codeberg_repo.json",
+		"codeberg.org/api/v1/repos/forgejo/forgejo/contents?ref=forgejo": "codeberg_contents.json",
+		"codeberg.org/forgejo/forgejo/raw/branch/forgejo/README.md":      "codeberg_readme.md",
+	}))
+
+	result, err := f.Fetch(context.Background(), "https://codeberg.org/forgejo/forgejo", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "gitea", result.Extractor)
+	assert.Equal(t, "forgejo/forgejo: Beyond coding. We forge.", result.Title)
+	assert.Contains(t, result.Content, "<h1>Welcome to Forgejo</h1>")
+	assert.Contains(t, result.Content, `<img src="https://codeberg.org/forgejo/forgejo/raw/branch/forgejo/assets/logo.svg" alt="Logo"/>`)
+	assert.Contains(t, result.Content, `<a href="https://codeberg.org/forgejo/forgejo/src/branch/forgejo/docs/README.md">the docs</a>`)
+}
+
+func TestExtract_MastodonThread(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"mastodon.social/api/v1/statuses/111782400355046723":         "mastodon_status.json",
+		"mastodon.social/api/v1/statuses/111782400355046723/context": "mastodon_context.json",
+	}))
+
+	result, err := f.Fetch(context.Background(), "https://mastodon.social/@Gargron/111782400355046723", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "mastodon", result.Extractor)
+	assert.Equal(t, "Eugen Rochko: Second, the fediverse keeps growing: more servers than ever run Mastodon.", result.Title)
+	assert.Equal(t, "https://mastodon.social/@Gargron/111782400355046723", result.URL)
+
+	// The author's posts in order; other people's replies are left out
+	assert.Regexp(t, `(?s)A few notes.*Second, the fediverse.*Third, and finally`, result.Text)
+	assert.NotContains(t, result.Content, "Great thread from someone else")
+	assert.Contains(t, result.Content, `alt="Chart of monthly active users"`)
+}
+
+func TestExtract_FallsBackToGenericExtraction(t *testing.T) {
+	page := fixture(t, "article.html")
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "export.arxiv.org":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write(fixture(t, "extractors/arxiv_error.xml"))
+		case "api.github.com":
+			http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+		}
+	}))
+
+	for _, link := range []string{
+		"https://arxiv.org/abs/2401.99999",
+		"https://github.com/user/repo",
+		"https://mastodon.social/about", // Not a status URL
+	} {
+		result, err := f.Fetch(context.Background(), link, Options{})
+		require.NoError(t, err, link)
+		assert.Empty(t, result.Extractor, link)
+		assert.Equal(t, "Postgres 17 Released", result.Title, link)
+	}
+	assert.Zero(t, tracker.open.Load())
+}
+
+func TestGitHubReadme_Subdirectory(t *testing.T) {
+	f, _ := newTestFetcher(t, apiFixtures(t, map[string]string{
+		"github.test/api/repos/rajeshkumarblr/hn_station":                      "github_repo.json",
+		"github.test/api/repos/rajeshkumarblr/hn_station/readme/docs?ref=v1.2": "github_readme_docs.json",
+	}))
+	env := map[string]string{"GITHUB_API_URL": "http://github.test/api/"}
+	github := GitHubFromEnv(func(key string) string { return env[key] }, f)
+
+	readme, err := github.Readme(context.Background(), f, "rajeshkumarblr", "hn_station", "v1.2", "docs/")
+	require.NoError(t, err)
+	assert.Equal(t, "docs/README.rst", readme.Path)
+	assert.Equal(t, "https://github.com/rajeshkumarblr/hn_station/blob/v1.2/docs/README.rst", readme.URL)
+	assert.Equal(t, "https://raw.githubusercontent.com/rajeshkumarblr/hn_station/v1.2/docs/", readme.ImageBase)
+
+	// Formats other than Markdown are shown as text
+	rendered, err := readme.HTML()
+	require.NoError(t, err)
+	assert.Equal(t, "<pre>Documentation\n=============\n\n.. image:: diagram.png\n\nBuild with ``make docs``.\n</pre>", rendered)
+}
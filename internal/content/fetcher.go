@@ -1,34 +1,162 @@
 package content
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html/charset"
 )
 
 // FetchResult contains the result of an article fetch
 type FetchResult struct {
-	Content   string
-	Title     string
-	CanIframe bool
+	Content    string
+	Text       string // Plain-text rendition of Content
+	Title      string
+	CanIframe  bool
+	StatusCode int
+	URL        string  // Final URL after redirects
+	Extractor  string  // Site-specific extractor that produced the result, if any
+	Outcome    Outcome // Whether the page is the article or a wall in front of it
+	Metadata   Metadata
 }
 
-// FetchArticle attempts to fetch and parse the article content.
-func FetchArticle(urlStr string) (*FetchResult, error) {
-	parsedURL, err := url.Parse(urlStr)
+// Options tune a single fetch. Zero values use the defaults.
+type Options struct {
+	MaxBytes    int64       // Limit on the decompressed body; default MaxBodyBytes, or MaxPDFBytes for PDFs
+	AcceptTypes []string    // Allowed media types; default ArticleTypes
+	Header      http.Header // Extra request headers
+}
+
+// ArticleTypes are the media types Fetch accepts by default.
+var ArticleTypes = []string{"text/html", "application/xhtml+xml", "text/plain", "text/markdown", "application/pdf"}
+
+const (
+	DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	DefaultTimeout   = 30 * time.Second
+)
+
+// Response is a fetched body, decompressed and decoded to UTF-8.
+type Response struct {
+	URL        *url.URL // Final URL after redirects
+	StatusCode int
+	Header     http.Header
+	MediaType  string
+	Body       []byte // Empty for error statuses
+}
+
+// Fetcher retrieves user-supplied URLs. It is safe for concurrent use.
+type Fetcher struct {
+	UserAgent  string
+	Timeout    time.Duration // Per fetch, on top of the caller's context
+	Extractors *Registry     // Site-specific extractors tried before generic extraction
+
+	client        *http.Client
+	trustedClient *http.Client    // For origins passed to Trust
+	trusted       map[string]bool // Keyed by origin
+}
+
+// NewFetcher returns a Fetcher that sends requests through transport, or
+// through the SSRF-safe transport when transport is nil. Scheme, port and
+// redirect checks always apply; an injected transport is responsible for
+// address checks.
+func NewFetcher(transport http.RoundTripper) *Fetcher {
+	trustedTransport := transport
+	if transport == nil {
+		transport = NewSafeTransport()
+		trustedTransport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	f := &Fetcher{
+		UserAgent:  DefaultUserAgent,
+		Timeout:    DefaultTimeout,
+		Extractors: DefaultExtractors(),
+		client: &http.Client{
+			Transport:     transport,
+			CheckRedirect: checkRedirect,
+		},
+		trusted: make(map[string]bool),
+	}
+	f.trustedClient = &http.Client{
+		Transport:     trustedTransport,
+		CheckRedirect: f.checkTrustedRedirect,
+	}
+	return f
+}
+
+// Trust exempts an operator-configured origin, such as a local stand-in for
+// an API, from the address and port checks applied to user-supplied URLs.
+// Redirects from it may only lead to other trusted origins. Call Trust
+// before the Fetcher is shared; it is not safe for concurrent use.
+func (f *Fetcher) Trust(base string) {
+	if u, err := url.Parse(base); err == nil && u.Host != "" {
+		f.trusted[origin(u)] = true
+	}
+}
+
+func origin(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// clientFor returns the client for a URL, checking untrusted URLs.
+func (f *Fetcher) clientFor(u *url.URL) (*http.Client, error) {
+	if f.trusted[origin(u)] {
+		return f.trustedClient, nil
+	}
+	if err := CheckURL(u); err != nil {
+		return nil, err
+	}
+	return f.client, nil
+}
+
+func (f *Fetcher) checkTrustedRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", MaxRedirects)
+	}
+	if !f.trusted[origin(req.URL)] {
+		return fmt.Errorf("%w: redirect from a trusted origin to %s", ErrBlockedURL, req.URL.Host)
+	}
+	return nil
+}
+
+// Get fetches a URL and returns its body decoded to UTF-8. Responses with
+// an error status are returned without a body rather than as errors.
+func (f *Fetcher) Get(ctx context.Context, rawURL string, opts Options) (*Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client, err := f.clientFor(u)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
 	}
-	req, _ := http.NewRequest("GET", urlStr, nil)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range opts.Header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	// Setting Accept-Encoding turns off the transport's own gzip handling
+	req.Header.Set("Accept-Encoding", "gzip, br")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -36,39 +164,172 @@ func FetchArticle(urlStr string) (*FetchResult, error) {
 	}
 	defer resp.Body.Close()
 
-	// GitHub Handling: If 404 on a repo URL, try raw README
-	if resp.StatusCode == 404 && strings.Contains(urlStr, "github.com") {
-		// Convert https://github.com/user/repo -> https://raw.githubusercontent.com/user/repo/master/README.md
-		if !strings.Contains(urlStr, "/blob/") && !strings.Contains(urlStr, "/tree/") {
-			rawURL := strings.Replace(urlStr, "github.com", "raw.githubusercontent.com", 1)
-			rawURL = strings.TrimSuffix(rawURL, "/") + "/master/README.md"
-
-			// Retry with raw URL
-			req, _ = http.NewRequest("GET", rawURL, nil)
-			resp, err = client.Do(req)
-			if err == nil && resp.StatusCode == 200 {
-				defer resp.Body.Close()
-				bodyBytes, _ := io.ReadAll(resp.Body)
-				return &FetchResult{
-					Content:   string(bodyBytes),
-					Title:     "GitHub README",
-					CanIframe: false,
-				}, nil
-			}
-			// If master fails, try main
-			rawURLMain := strings.Replace(rawURL, "master", "main", 1)
-			req, _ = http.NewRequest("GET", rawURLMain, nil)
-			resp, err = client.Do(req)
-			if err == nil && resp.StatusCode == 200 {
-				defer resp.Body.Close()
-				bodyBytes, _ := io.ReadAll(resp.Body)
-				return &FetchResult{
-					Content:   string(bodyBytes),
-					Title:     "GitHub README",
-					CanIframe: false,
-				}, nil
-			}
+	result := &Response{
+		URL:        resp.Request.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if resp.StatusCode >= 400 {
+		return result, nil
+	}
+
+	// A declared type is checked before the body is read; a missing one is
+	// sniffed from the body
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		if result.MediaType, err = checkContentType(contentType, opts.acceptTypes()); err != nil {
+			return nil, err
+		}
+	}
+	body, err := readBody(resp, opts.maxBytes(contentType))
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+		if result.MediaType, err = checkContentType(contentType, opts.acceptTypes()); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Body = body
+	if isTextType(result.MediaType) {
+		if result.Body, err = decodeCharset(body, contentType); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Check requests a URL without reading the body and returns the final
+// response status. Servers that reject HEAD, or answer it with a status
+// saying the page is missing, are asked again with GET.
+func (f *Fetcher) Check(ctx context.Context, rawURL string) (int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+	client, err := f.clientFor(u)
+	if err != nil {
+		return 0, err
+	}
+
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	status := 0
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("User-Agent", f.UserAgent)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
 		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if !retryWithGet[status] {
+			break
+		}
+	}
+	return status, nil
+}
+
+// retryWithGet are the HEAD statuses not trusted without a GET; some
+// servers and frameworks route only GET and answer HEAD with 404.
+var retryWithGet = map[int]bool{
+	http.StatusForbidden:        true,
+	http.StatusNotFound:         true,
+	http.StatusMethodNotAllowed: true,
+	http.StatusGone:             true,
+	http.StatusNotImplemented:   true,
+}
+
+// maxBytes allows larger bodies for PDFs, which are commonly several megabytes.
+func (o Options) maxBytes(contentType string) int64 {
+	if o.MaxBytes > 0 {
+		return o.MaxBytes
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/pdf" {
+		return MaxPDFBytes
+	}
+	return MaxBodyBytes
+}
+
+func isTextType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/xhtml+xml"
+}
+
+func (o Options) acceptTypes() []string {
+	if len(o.AcceptTypes) > 0 {
+		return o.AcceptTypes
+	}
+	return ArticleTypes
+}
+
+// readBody decompresses the body and reads at most maxBytes of it. The
+// limit applies after decompression, so small compressed payloads cannot
+// expand without bound.
+func readBody(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	var reader io.Reader = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, maxBytes)
+	}
+	return body, nil
+}
+
+// decodeCharset converts a text body to UTF-8 using the charset from the
+// Content-Type header, a BOM or a <meta> declaration, in that order.
+func decodeCharset(body []byte, contentType string) ([]byte, error) {
+	enc, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return body, nil
+	}
+	return enc.NewDecoder().Bytes(body)
+}
+
+// Fetch retrieves a URL and extracts the article content, using a
+// site-specific extractor when one handles the URL.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string, opts Options) (*FetchResult, error) {
+	if result, err := f.extract(ctx, rawURL); result != nil || err != nil {
+		return result, err
+	}
+
+	resp, err := f.Get(ctx, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return &FetchResult{StatusCode: resp.StatusCode, URL: resp.URL.String(), Outcome: classifyError(resp)}, nil
 	}
 
 	// 1. Check Iframe Compatibility
@@ -83,27 +344,51 @@ func FetchArticle(urlStr string) (*FetchResult, error) {
 		canIframe = false
 	}
 
-	// 2. Read Body
-	// Limit to 2MB to prevent memory exhaustion
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
-	if err != nil {
-		return nil, err
+	if resp.MediaType == "application/pdf" {
+		title, text, err := extractPDF(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &FetchResult{
+			Content:    pdfHTML(title, text),
+			Text:       text,
+			Title:      title,
+			CanIframe:  canIframe,
+			StatusCode: resp.StatusCode,
+			URL:        resp.URL.String(),
+			Outcome:    OutcomeArticle,
+			Metadata:   Metadata{Favicon: defaultFavicon(resp.URL)},
+		}, nil
 	}
 
-	// 3. Attempt Parsing with go-readability
-	article, err := readability.FromReader(strings.NewReader(string(bodyBytes)), parsedURL)
+	metadata := ExtractMetadata(resp.Body, resp.URL)
+
+	// 2. Attempt Parsing with go-readability, resolving links against the final URL
+	article, err := readability.FromReader(bytes.NewReader(resp.Body), resp.URL)
 	if err == nil && article.Content != "" {
+		text := PlainText(article.Content)
 		return &FetchResult{
-			Content:   article.Content,
-			Title:     article.Title,
-			CanIframe: canIframe,
+			Content:    article.Content,
+			Text:       text,
+			Title:      article.Title,
+			CanIframe:  canIframe,
+			StatusCode: resp.StatusCode,
+			URL:        resp.URL.String(),
+			Outcome:    Classify(resp, text),
+			Metadata:   metadata,
 		}, nil
 	}
 
-	// 4. Fallback to Raw HTML
+	// 3. Fallback to Raw HTML
+	text := PlainText(string(resp.Body))
 	return &FetchResult{
-		Content:   string(bodyBytes),
-		Title:     "Unknown Title",
-		CanIframe: canIframe,
+		Content:    string(resp.Body),
+		Text:       text,
+		Title:      "Unknown Title",
+		CanIframe:  canIframe,
+		StatusCode: resp.StatusCode,
+		URL:        resp.URL.String(),
+		Outcome:    Classify(resp, text),
+		Metadata:   metadata,
 	}, nil
 }
@@ -0,0 +1,265 @@
+package content
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trackingTransport counts response bodies that were never closed.
+type trackingTransport struct {
+	rt   http.RoundTripper
+	open atomic.Int64
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	t      *trackingTransport
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	if b.closed.CompareAndSwap(false, true) {
+		b.t.open.Add(-1)
+	}
+	return b.ReadCloser.Close()
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.open.Add(1)
+	resp.Body = &trackedBody{ReadCloser: resp.Body, t: t}
+	return resp, nil
+}
+
+// newTestFetcher routes every host to handler, so tests can use public-looking
+// URLs while the SSRF-safe transport is swapped out. https URLs are served
+// over plain HTTP.
+func newTestFetcher(t *testing.T, handler http.Handler) (*Fetcher, *trackingTransport) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, ts.Listener.Addr().String())
+		},
+	}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	tracker := &trackingTransport{rt: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+		return transport.RoundTrip(req)
+	})}
+	return NewFetcher(tracker), tracker
+}
+
+func fixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFetch_ExtractsArticle(t *testing.T) {
+	page := fixture(t, "article.html")
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/blog/postgres-17", http.StatusMovedPermanently)
+		case "/blog/postgres-17":
+			assert.Equal(t, DefaultUserAgent, r.Header.Get("User-Agent"))
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("X-Frame-Options", "DENY")
+			w.Write(page)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := f.Fetch(context.Background(), "http://example.com/old", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres 17 Released", result.Title)
+	assert.Equal(t, "http://example.com/blog/postgres-17", result.URL)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.False(t, result.CanIframe)
+	assert.Contains(t, result.Text, "incremental backups")
+	assert.Contains(t, result.Text, "naïve café résumé")
+	assert.NotContains(t, result.Content, "window.track")
+	assert.Contains(t, result.Content, `href="http://example.com/docs/release-17"`)
+	assert.Equal(t, "Incremental backups, faster vacuum & JSON_TABLE.", result.Metadata.Description)
+	assert.Equal(t, "http://example.com/images/pg17-card.png", result.Metadata.Image)
+	assert.Equal(t, "http://example.com/icons/favicon-32.png", result.Metadata.Favicon)
+	assert.Zero(t, tracker.open.Load())
+}
+
+func TestFetch_DecodesCharsets(t *testing.T) {
+	cases := map[string]string{
+		"/latin1":      "text/html",                       // <meta charset="iso-8859-1">
+		"/windows1252": "text/html; charset=windows-1252", // header only
+	}
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", cases[r.URL.Path])
+		w.Write(fixture(t, strings.TrimPrefix(r.URL.Path, "/")+".html"))
+	}))
+
+	result, err := f.Fetch(context.Background(), "http://example.com/latin1", Options{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "naïve café résumé")
+
+	result, err = f.Fetch(context.Background(), "http://example.com/windows1252", Options{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "“naïve” café résumé")
+}
+
+func TestGet_Decompresses(t *testing.T) {
+	page := fixture(t, "article.html")
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip, br", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		var buf bytes.Buffer
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(&buf)
+			gz.Write(page)
+			gz.Close()
+		case "/br":
+			w.Header().Set("Content-Encoding", "br")
+			br := brotli.NewWriter(&buf)
+			br.Write(page)
+			br.Close()
+		}
+		w.Write(buf.Bytes())
+	}))
+
+	for _, path := range []string{"/gzip", "/br"} {
+		resp, err := f.Get(context.Background(), "http://example.com"+path, Options{})
+		require.NoError(t, err, path)
+		assert.Equal(t, page, resp.Body, path)
+		assert.Equal(t, "text/html", resp.MediaType, path)
+	}
+}
+
+func TestGet_EnforcesLimits(t *testing.T) {
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bomb":
+			// Tiny on the wire, large once decompressed
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write(bytes.Repeat([]byte("a"), 1024*1024))
+			gz.Close()
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte{0, 1, 2})
+		case "/video":
+			// Never sent: the declared type is enough to refuse it
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("Content-Length", "1073741824")
+			w.WriteHeader(http.StatusOK)
+		case "/private":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	ctx := context.Background()
+
+	_, err := f.Get(ctx, "http://example.com/bomb", Options{MaxBytes: 64 * 1024})
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = f.Get(ctx, "http://example.com/binary", Options{})
+	assert.ErrorIs(t, err, ErrContentType)
+
+	_, err = f.Get(ctx, "http://example.com/video", Options{})
+	assert.ErrorIs(t, err, ErrContentType)
+
+	_, err = f.Get(ctx, "http://example.com/private", Options{})
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+
+	_, err = f.Get(ctx, "http://example.com/loop", Options{})
+	assert.ErrorContains(t, err, "redirects")
+
+	assert.Zero(t, tracker.open.Load())
+}
+
+func TestGet_StopsOnCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := f.Get(ctx, "http://example.com/slow", Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestFetch_ErrorStatus(t *testing.T) {
+	f, tracker := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+
+	result, err := f.Fetch(context.Background(), "http://example.com/a", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGone, result.StatusCode)
+	assert.Empty(t, result.Content)
+	assert.Zero(t, tracker.open.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestFetch_ExtractsPDF(t *testing.T) {
+	paper := fixture(t, "paper.pdf")
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(paper)
+	}))
+
+	result, err := f.Fetch(context.Background(), "http://arxiv.example/pdf/1234.pdf", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "Tiny Papers: Small Models, Narrow Tasks", result.Title)
+	assert.Equal(t, "A Study of Tiny Papers\n\n"+
+		"Large language models are trained on vast corpora of text. We show that small models can match them on narrow tasks when the training data is carefully selected.\n\n"+
+		"Our second paragraph describes the evaluation setup and results.", result.Text)
+	assert.Contains(t, result.Content, "<h1>Tiny Papers: Small Models, Narrow Tasks</h1>")
+	assert.Contains(t, result.Content, "<p>Our second paragraph describes the evaluation setup and results.</p>")
+}
+
+func TestFetch_InvalidPDF(t *testing.T) {
+	f, _ := newTestFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4 truncated"))
+	}))
+
+	_, err := f.Fetch(context.Background(), "http://example.com/broken.pdf", Options{})
+	assert.Error(t, err)
+}
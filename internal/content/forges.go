@@ -0,0 +1,162 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	DefaultGitLabAPI   = "https://gitlab.com/api/v4"
+	DefaultCodebergAPI = "https://codeberg.org/api/v1"
+)
+
+// getText fetches a raw file and returns it for a 200 response.
+func (f *Fetcher) getText(ctx context.Context, rawURL string) (string, error) {
+	resp, err := f.Get(ctx, rawURL, Options{AcceptTypes: []string{"text/plain", "text/markdown", "application/octet-stream"}})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return string(resp.Body), nil
+}
+
+// GitLabExtractor renders GitLab project READMEs through the GitLab API.
+type GitLabExtractor struct {
+	APIBase string
+}
+
+func (e *GitLabExtractor) Name() string { return "gitlab" }
+
+type gitLabProject struct {
+	ID                int    `json:"id"`
+	NameWithNamespace string `json:"name_with_namespace"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	DefaultBranch     string `json:"default_branch"`
+	ReadmeURL         string `json:"readme_url"`
+	WebURL            string `json:"web_url"`
+}
+
+func (e *GitLabExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	// Project pages are /group/.../project; sub-pages follow a /-/ separator
+	parts := pathParts(u)
+	for i, p := range parts {
+		if p == "-" {
+			if i+1 < len(parts) && parts[i+1] != "tree" {
+				return nil, ErrNotHandled
+			}
+			parts = parts[:i]
+			break
+		}
+	}
+	if len(parts) < 2 {
+		return nil, ErrNotHandled
+	}
+
+	var project gitLabProject
+	if err := f.getJSON(ctx, e.APIBase+"/projects/"+url.PathEscape(strings.Join(parts, "/")), nil, &project); err != nil {
+		return nil, err
+	}
+
+	var readme string
+	// readme_url is {web_url}/-/blob/{branch}/{path}
+	if file, ok := strings.CutPrefix(project.ReadmeURL, project.WebURL+"/-/blob/"+project.DefaultBranch+"/"); ok {
+		raw, err := f.getText(ctx, fmt.Sprintf("%s/projects/%d/repository/files/%s/raw?ref=%s",
+			e.APIBase, project.ID, url.PathEscape(file), url.QueryEscape(project.DefaultBranch)))
+		if err != nil {
+			return nil, err
+		}
+		dir := strings.TrimSuffix(path.Dir(file), ".")
+		if dir != "" {
+			dir += "/"
+		}
+		readme, err = readmeHTML(path.Base(file), raw,
+			project.WebURL+"/-/blob/"+project.DefaultBranch+"/"+dir,
+			project.WebURL+"/-/raw/"+project.DefaultBranch+"/"+dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name := project.PathWithNamespace
+	return htmlResult(repoTitle(name, project.Description), repoPage(name, project.Description, readme), project.WebURL), nil
+}
+
+// GiteaExtractor renders repository READMEs from Gitea and Forgejo forges
+// such as Codeberg.
+type GiteaExtractor struct {
+	APIBase string
+}
+
+func (e *GiteaExtractor) Name() string { return "gitea" }
+
+type giteaRepo struct {
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+}
+
+type giteaEntry struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DownloadURL string `json:"download_url"`
+}
+
+func (e *GiteaExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	parts := pathParts(u)
+	if len(parts) != 2 && !(len(parts) > 2 && parts[2] == "src") {
+		return nil, ErrNotHandled
+	}
+	base := e.APIBase + "/repos/" + url.PathEscape(parts[0]) + "/" + url.PathEscape(parts[1])
+
+	var repo giteaRepo
+	if err := f.getJSON(ctx, base, nil, &repo); err != nil {
+		return nil, err
+	}
+	var entries []giteaEntry
+	if err := f.getJSON(ctx, base+"/contents?ref="+url.QueryEscape(repo.DefaultBranch), nil, &entries); err != nil {
+		return nil, err
+	}
+
+	var readme string
+	if entry := findReadme(entries); entry != nil {
+		raw, err := f.getText(ctx, entry.DownloadURL)
+		if err != nil {
+			return nil, err
+		}
+		readme, err = readmeHTML(entry.Name, raw,
+			repo.HTMLURL+"/src/branch/"+repo.DefaultBranch+"/",
+			repo.HTMLURL+"/raw/branch/"+repo.DefaultBranch+"/")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return htmlResult(repoTitle(repo.FullName, repo.Description), repoPage(repo.FullName, repo.Description, readme), repo.HTMLURL), nil
+}
+
+// findReadme picks the README from a directory listing, preferring Markdown.
+func findReadme(entries []giteaEntry) *giteaEntry {
+	var found *giteaEntry
+	for i := range entries {
+		e := &entries[i]
+		name := strings.ToLower(e.Name)
+		if e.Type != "file" || !strings.HasPrefix(name, "readme") {
+			continue
+		}
+		if strings.HasSuffix(name, ".md") || strings.HasSuffix(name, ".markdown") {
+			return e
+		}
+		if found == nil {
+			found = e
+		}
+	}
+	return found
+}
@@ -0,0 +1,302 @@
+package content
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const DefaultGitHubAPI = "https://api.github.com"
+
+// maxIssueComments bounds the discussion included for issues and PRs.
+const maxIssueComments = 50
+
+// GitHubExtractor renders repository READMEs and issue or pull request
+// threads through the GitHub REST API. Token is optional and raises the
+// API rate limit.
+type GitHubExtractor struct {
+	APIBase string
+	Token   string
+}
+
+func (e *GitHubExtractor) Name() string { return "github" }
+
+func (e *GitHubExtractor) header(accept string) http.Header {
+	h := http.Header{"Accept": {accept}, "X-Github-Api-Version": {"2022-11-28"}}
+	if e.Token != "" {
+		h.Set("Authorization", "Bearer "+e.Token)
+	}
+	return h
+}
+
+func (e *GitHubExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	parts := pathParts(u)
+	switch {
+	case len(parts) == 2:
+		return e.repo(ctx, f, parts[0], parts[1], "", "")
+	case len(parts) >= 4 && parts[2] == "tree":
+		return e.repo(ctx, f, parts[0], parts[1], parts[3], strings.Join(parts[4:], "/"))
+	case len(parts) >= 4 && (parts[2] == "issues" || parts[2] == "pull") && isDigits(parts[3]):
+		return e.issue(ctx, f, parts[0], parts[1], parts[3])
+	default:
+		return nil, ErrNotHandled
+	}
+}
+
+// GitHubFromEnv configures the GitHub extractor from GITHUB_API_URL, which
+// points at a local stand-in for the API, and GITHUB_TOKEN. The stand-in is
+// trusted by f, so it may listen on a private address or any port.
+func GitHubFromEnv(getenv func(string) string, f *Fetcher) *GitHubExtractor {
+	e := &GitHubExtractor{APIBase: DefaultGitHubAPI, Token: getenv("GITHUB_TOKEN")}
+	if base := strings.TrimRight(getenv("GITHUB_API_URL"), "/"); base != "" {
+		e.APIBase = base
+		f.Trust(base)
+	}
+	return e
+}
+
+type gitHubRepo struct {
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+}
+
+type gitHubReadme struct {
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GitHubReadme is a repository README and the bases for its relative URLs.
+type GitHubReadme struct {
+	Repo      string // owner/repo
+	Ref       string // Branch, tag or commit the README was read at
+	Path      string // Path within the repository, e.g. docs/README.rst
+	Source    string
+	URL       string // Blob view of the README
+	LinkBase  string // Blob view of the README's directory
+	ImageBase string // Raw files of the README's directory
+}
+
+// HTML renders the README with relative links and images made absolute.
+// Markdown is converted; other formats are shown preformatted. The result
+// is not sanitized.
+func (r *GitHubReadme) HTML() (string, error) {
+	return readmeHTML(r.Path, r.Source, r.LinkBase, r.ImageBase)
+}
+
+// Readme resolves the README of a repository directory, whatever its file
+// name. An empty ref means the default branch and an empty dir the root.
+func (e *GitHubExtractor) Readme(ctx context.Context, f *Fetcher, owner, repo, ref, dir string) (*GitHubReadme, error) {
+	info, err := e.repository(ctx, f, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return e.readme(ctx, f, info, ref, dir)
+}
+
+func (e *GitHubExtractor) repository(ctx context.Context, f *Fetcher, owner, repo string) (*gitHubRepo, error) {
+	var info gitHubRepo
+	err := f.getJSON(ctx, e.APIBase+"/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(repo), e.header("application/vnd.github+json"), &info)
+	return &info, err
+}
+
+func (e *GitHubExtractor) readme(ctx context.Context, f *Fetcher, info *gitHubRepo, ref, dir string) (*GitHubReadme, error) {
+	if ref == "" {
+		ref = info.DefaultBranch
+	}
+	endpoint := e.APIBase + "/repos/" + info.FullName + "/readme"
+	if dir = strings.Trim(dir, "/"); dir != "" {
+		endpoint += "/" + (&url.URL{Path: dir}).EscapedPath()
+	}
+	endpoint += "?ref=" + url.QueryEscape(ref)
+
+	var readme gitHubReadme
+	if err := f.getJSON(ctx, endpoint, e.header("application/vnd.github+json"), &readme); err != nil {
+		return nil, err
+	}
+	if readme.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected README encoding %q", readme.Encoding)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(readme.Content, "\n", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	readmeDir := path.Dir(readme.Path)
+	if readmeDir == "." {
+		readmeDir = ""
+	} else {
+		readmeDir += "/"
+	}
+	blob := "https://github.com/" + info.FullName + "/blob/" + ref + "/"
+	return &GitHubReadme{
+		Repo:      info.FullName,
+		Ref:       ref,
+		Path:      readme.Path,
+		Source:    string(body),
+		URL:       blob + readme.Path,
+		LinkBase:  blob + readmeDir,
+		ImageBase: "https://raw.githubusercontent.com/" + info.FullName + "/" + ref + "/" + readmeDir,
+	}, nil
+}
+
+func (e *GitHubExtractor) repo(ctx context.Context, f *Fetcher, owner, name, ref, dir string) (*FetchResult, error) {
+	info, err := e.repository(ctx, f, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A repository without a README still has a description
+	var readmeBody string
+	if readme, err := e.readme(ctx, f, info, ref, dir); err == nil {
+		if readmeBody, err = readme.HTML(); err != nil {
+			return nil, err
+		}
+	}
+	result := htmlResult(repoTitle(info.FullName, info.Description), repoPage(info.FullName, info.Description, readmeBody), info.HTMLURL)
+	result.Metadata = Metadata{Description: info.Description, SiteName: "GitHub", Author: owner}
+	return result, nil
+}
+
+type gitHubUser struct {
+	Login string `json:"login"`
+}
+
+type gitHubIssue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	State       string     `json:"state"`
+	BodyHTML    string     `json:"body_html"`
+	HTMLURL     string     `json:"html_url"`
+	User        gitHubUser `json:"user"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PullRequest *struct{}  `json:"pull_request"`
+}
+
+type gitHubComment struct {
+	BodyHTML  string     `json:"body_html"`
+	User      gitHubUser `json:"user"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// issue renders an issue or pull request with its first comments. The
+// issues API serves both.
+func (e *GitHubExtractor) issue(ctx context.Context, f *Fetcher, owner, repo, number string) (*FetchResult, error) {
+	base := e.APIBase + "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues/" + number
+	accept := e.header("application/vnd.github.html+json")
+
+	var issue gitHubIssue
+	if err := f.getJSON(ctx, base, accept, &issue); err != nil {
+		return nil, err
+	}
+	var comments []gitHubComment
+	if err := f.getJSON(ctx, fmt.Sprintf("%s/comments?per_page=%d", base, maxIssueComments), accept, &comments); err != nil {
+		return nil, err
+	}
+
+	kind := "Issue"
+	if issue.PullRequest != nil {
+		kind = "Pull request"
+	}
+
+	var b strings.Builder
+	b.WriteString("<h1>" + html.EscapeString(issue.Title) + "</h1>")
+	b.WriteString(fmt.Sprintf("<p>%s #%d by %s, %s, opened %s</p>", kind, issue.Number,
+		html.EscapeString(issue.User.Login), html.EscapeString(issue.State), issue.CreatedAt.Format("2006-01-02")))
+	b.WriteString(issue.BodyHTML)
+	if len(comments) > 0 {
+		b.WriteString("<h2>Comments</h2>")
+		for _, c := range comments {
+			b.WriteString("<h3>" + html.EscapeString(c.User.Login) + " on " + c.CreatedAt.Format("2006-01-02") + "</h3>")
+			b.WriteString(c.BodyHTML)
+		}
+	}
+
+	title := fmt.Sprintf("%s · %s/%s#%d", issue.Title, owner, repo, issue.Number)
+	return htmlResult(title, b.String(), issue.HTMLURL), nil
+}
+
+// GistExtractor renders gists through the GitHub API: Markdown files are
+// converted, other files shown as code.
+type GistExtractor struct {
+	GitHub *GitHubExtractor
+}
+
+func (e *GistExtractor) Name() string { return "gist" }
+
+type gist struct {
+	Description string     `json:"description"`
+	HTMLURL     string     `json:"html_url"`
+	Owner       gitHubUser `json:"owner"`
+	Files       map[string]struct {
+		Filename string `json:"filename"`
+		Language string `json:"language"`
+		Content  string `json:"content"`
+	} `json:"files"`
+}
+
+func (e *GistExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	parts := pathParts(u)
+	if len(parts) == 0 || len(parts) > 2 || !isHex(parts[len(parts)-1]) {
+		return nil, ErrNotHandled
+	}
+
+	var g gist
+	if err := f.getJSON(ctx, e.GitHub.APIBase+"/gists/"+parts[len(parts)-1], e.GitHub.header("application/vnd.github+json"), &g); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(g.Files))
+	for name := range g.Files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	title := g.Description
+	if title == "" && len(names) > 0 {
+		title = names[0]
+	}
+
+	var b strings.Builder
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>")
+	b.WriteString("<p>Gist by " + html.EscapeString(g.Owner.Login) + "</p>")
+	for _, name := range names {
+		file := g.Files[name]
+		b.WriteString("<h2>" + html.EscapeString(name) + "</h2>")
+		if ext := strings.ToLower(path.Ext(name)); ext == ".md" || ext == ".markdown" {
+			rendered, err := RenderMarkdown(file.Content, g.HTMLURL, g.HTMLURL)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(rendered)
+			continue
+		}
+		class := ""
+		if file.Language != "" {
+			class = ` class="language-` + html.EscapeString(strings.ToLower(file.Language)) + `"`
+		}
+		b.WriteString("<pre><code" + class + ">" + html.EscapeString(file.Content) + "</code></pre>")
+	}
+	return htmlResult(title, b.String(), g.HTMLURL), nil
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package content
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// markdownRenderer renders GitHub Flavored Markdown. Raw HTML is kept
+// because READMEs rely on it; output is sanitized before it is served.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// RenderMarkdown converts Markdown to HTML. Relative link targets are
+// resolved against linkBase and relative image sources against imageBase,
+// which differ on code forges (a blob view versus the raw file).
+func RenderMarkdown(source, linkBase, imageBase string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return resolveURLs(buf.String(), linkBase, imageBase), nil
+}
+
+// resolveURLs makes relative a[href] and img[src] values absolute.
+func resolveURLs(doc, linkBase, imageBase string) string {
+	links, _ := url.Parse(linkBase)
+	images, _ := url.Parse(imageBase)
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(doc), context)
+	if err != nil {
+		return doc
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.A:
+				resolveAttr(n, "href", links)
+			case atom.Img:
+				resolveAttr(n, "src", images)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		walk(n)
+		html.Render(&b, n)
+	}
+	return b.String()
+}
+
+func resolveAttr(n *html.Node, key string, base *url.URL) {
+	if base == nil || !base.IsAbs() {
+		return
+	}
+	for i, a := range n.Attr {
+		if a.Key != key {
+			continue
+		}
+		ref, err := url.Parse(strings.TrimSpace(a.Val))
+		if err != nil || ref.IsAbs() || strings.HasPrefix(a.Val, "#") {
+			continue
+		}
+		n.Attr[i].Val = base.ResolveReference(ref).String()
+	}
+}
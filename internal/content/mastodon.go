@@ -0,0 +1,133 @@
+package content
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MastodonExtractor renders Mastodon statuses, including the author's own
+// replies that continue a thread, through the instance's public API. It is
+// registered for every host and only handles status URL shapes.
+type MastodonExtractor struct{}
+
+func (e *MastodonExtractor) Name() string { return "mastodon" }
+
+type mastodonAccount struct {
+	ID          string `json:"id"`
+	Acct        string `json:"acct"`
+	DisplayName string `json:"display_name"`
+}
+
+type mastodonStatus struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Content     string          `json:"content"`
+	SpoilerText string          `json:"spoiler_text"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Account     mastodonAccount `json:"account"`
+	Reblog      *mastodonStatus `json:"reblog"`
+	Media       []mastodonMedia `json:"media_attachments"`
+	InReplyToID *string         `json:"in_reply_to_id"`
+}
+
+type mastodonMedia struct {
+	Type        string `json:"type"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// mastodonStatusID extracts the ID from /@user/{id} and /users/{user}/statuses/{id}.
+func mastodonStatusID(u *url.URL) (string, bool) {
+	parts := pathParts(u)
+	switch {
+	case len(parts) == 2 && strings.HasPrefix(parts[0], "@") && isDigits(parts[1]):
+		return parts[1], true
+	case len(parts) == 4 && parts[0] == "users" && parts[2] == "statuses" && isDigits(parts[3]):
+		return parts[3], true
+	default:
+		return "", false
+	}
+}
+
+func (e *MastodonExtractor) Extract(ctx context.Context, f *Fetcher, u *url.URL) (*FetchResult, error) {
+	id, ok := mastodonStatusID(u)
+	if !ok {
+		return nil, ErrNotHandled
+	}
+	api := u.Scheme + "://" + u.Host + "/api/v1/statuses/" + id
+
+	var status mastodonStatus
+	if err := f.getJSON(ctx, api, nil, &status); err != nil {
+		return nil, err
+	}
+	if status.Reblog != nil {
+		status = *status.Reblog
+	}
+
+	// The thread is the author's own posts around this one; replies from
+	// others are left to the HN discussion.
+	thread := []mastodonStatus{status}
+	var around struct {
+		Ancestors   []mastodonStatus `json:"ancestors"`
+		Descendants []mastodonStatus `json:"descendants"`
+	}
+	if err := f.getJSON(ctx, api+"/context", nil, &around); err == nil {
+		thread = append(selfThread(around.Ancestors, status.Account.ID), status)
+		thread = append(thread, selfThread(around.Descendants, status.Account.ID)...)
+	}
+
+	author := status.Account.DisplayName
+	if author == "" {
+		author = status.Account.Acct
+	}
+
+	var b strings.Builder
+	b.WriteString("<p>" + html.EscapeString(author) + " (@" + html.EscapeString(status.Account.Acct) + ")</p>")
+	for _, s := range thread {
+		b.WriteString("<article>")
+		if s.SpoilerText != "" {
+			b.WriteString("<p><strong>" + html.EscapeString(s.SpoilerText) + "</strong></p>")
+		}
+		b.WriteString(s.Content)
+		for _, m := range s.Media {
+			if m.Type == "image" || m.Type == "gifv" {
+				b.WriteString(`<p><img src="` + html.EscapeString(m.URL) + `" alt="` + html.EscapeString(m.Description) + `"></p>`)
+			}
+		}
+		b.WriteString("<p><time>" + s.CreatedAt.Format("2006-01-02 15:04") + "</time></p>")
+		b.WriteString("</article>")
+	}
+
+	body := b.String()
+	title := author + ": " + truncateWords(PlainText(status.Content), 80)
+	return htmlResult(title, body, status.URL), nil
+}
+
+// selfThread keeps the statuses posted by the given account.
+func selfThread(statuses []mastodonStatus, accountID string) []mastodonStatus {
+	var out []mastodonStatus
+	for _, s := range statuses {
+		if s.Account.ID == accountID {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// truncateWords shortens text to at most max characters, on a word boundary
+// when there is one. It never splits a multi-byte character.
+func truncateWords(text string, max int) string {
+	text = collapseSpace(strings.TrimSpace(text))
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	head := string([]rune(text)[:max])
+	if cut := strings.LastIndex(head, " "); cut > 0 {
+		head = head[:cut]
+	}
+	return head + "…"
+}
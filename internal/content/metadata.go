@@ -0,0 +1,270 @@
+package content
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxDescriptionLen bounds stored descriptions; some sites put whole
+// articles in og:description.
+const maxDescriptionLen = 500
+
+// Metadata is the preview information a page declares about itself through
+// Open Graph, Twitter card and standard <meta> tags.
+type Metadata struct {
+	Description string
+	Image       string // Absolute URL
+	SiteName    string
+	Author      string
+	PublishedAt *time.Time
+	Favicon     string // Absolute URL; /favicon.ico when the page declares none
+}
+
+// metaKeys lists the tags each field is read from, highest precedence first.
+var metaKeys = struct {
+	description, image, siteName, author, published []string
+}{
+	description: []string{"og:description", "twitter:description", "description"},
+	image:       []string{"og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"},
+	siteName:    []string{"og:site_name", "application-name"},
+	author:      []string{"author", "article:author", "og:article:author", "twitter:creator", "parsely-author", "sailthru.author"},
+	published:   []string{"article:published_time", "og:article:published_time", "datepublished", "date", "dc.date.issued", "parsely-pub-date", "sailthru.date"},
+}
+
+// ExtractMetadata reads preview metadata from an HTML document. Relative
+// URLs are resolved against base, the page's final URL.
+func ExtractMetadata(doc []byte, base *url.URL) Metadata {
+	meta := make(map[string]string)
+	var icons []icon
+
+	z := html.NewTokenizer(bytes.NewReader(doc))
+scan:
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break scan
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		switch atom.Lookup(name) {
+		case atom.Body:
+			// Everything we read lives in <head>
+			break scan
+		case atom.Meta:
+			if hasAttr {
+				readMeta(z, meta)
+			}
+		case atom.Link:
+			if hasAttr {
+				if ic, ok := readIcon(z); ok {
+					icons = append(icons, ic)
+				}
+			}
+		}
+	}
+
+	m := Metadata{
+		Description: truncateWords(first(meta, metaKeys.description), maxDescriptionLen),
+		Image:       firstURL(meta, metaKeys.image, base),
+		SiteName:    first(meta, metaKeys.siteName),
+		Author:      metaAuthor(meta),
+		PublishedAt: parseMetaTime(first(meta, metaKeys.published)),
+		Favicon:     resolve(base, bestIcon(icons)),
+	}
+	if m.Favicon == "" {
+		m.Favicon = defaultFavicon(base)
+	}
+	return m
+}
+
+// defaultFavicon is the conventional /favicon.ico of a URL's origin.
+func defaultFavicon(u *url.URL) string {
+	if u == nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/favicon.ico"
+}
+
+// readMeta records a <meta> tag keyed by its lower-cased property, name or
+// itemprop. The first occurrence of a key wins.
+func readMeta(z *html.Tokenizer, meta map[string]string) {
+	var key, value string
+	for {
+		k, v, more := z.TagAttr()
+		switch string(k) {
+		case "property", "name", "itemprop":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(v)))
+			}
+		case "content":
+			value = strings.TrimSpace(string(v))
+		}
+		if !more {
+			break
+		}
+	}
+	if key == "" || value == "" {
+		return
+	}
+	if _, ok := meta[key]; !ok {
+		meta[key] = value
+	}
+}
+
+type icon struct {
+	href  string
+	size  int  // Largest declared dimension; 0 when unknown
+	apple bool // apple-touch-icon, used only when nothing else is declared
+}
+
+func readIcon(z *html.Tokenizer) (icon, bool) {
+	var rel, href, sizes string
+	for {
+		k, v, more := z.TagAttr()
+		switch string(k) {
+		case "rel":
+			rel = strings.ToLower(string(v))
+		case "href":
+			href = strings.TrimSpace(string(v))
+		case "sizes":
+			sizes = strings.ToLower(string(v))
+		}
+		if !more {
+			break
+		}
+	}
+	if href == "" {
+		return icon{}, false
+	}
+	var ic icon
+	for _, r := range strings.Fields(rel) {
+		switch r {
+		case "icon":
+			ic.href = href
+		case "apple-touch-icon", "apple-touch-icon-precomposed":
+			ic.href, ic.apple = href, true
+		}
+	}
+	if ic.href == "" {
+		return icon{}, false
+	}
+	for _, s := range strings.Fields(sizes) {
+		if w, _, ok := strings.Cut(s, "x"); ok {
+			if n, err := strconv.Atoi(w); err == nil && n > ic.size {
+				ic.size = n
+			}
+		}
+	}
+	return ic, true
+}
+
+// bestIcon prefers a regular icon closest to 32px, which suits list rows,
+// over apple-touch-icons.
+func bestIcon(icons []icon) string {
+	best := -1
+	score := func(ic icon) int {
+		s := 0
+		if ic.apple {
+			s += 10000
+		}
+		if ic.size > 0 {
+			d := ic.size - 32
+			if d < 0 {
+				d = -d
+			}
+			s += d
+		} else {
+			s += 64 // Unknown size, often a multi-size .ico
+		}
+		return s
+	}
+	for i, ic := range icons {
+		if best < 0 || score(ic) < score(icons[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return icons[best].href
+}
+
+func first(meta map[string]string, keys []string) string {
+	for _, k := range keys {
+		if v := meta[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstURL returns the first value that resolves to an http(s) URL.
+func firstURL(meta map[string]string, keys []string, base *url.URL) string {
+	for _, k := range keys {
+		if u := resolve(base, meta[k]); u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// metaAuthor skips profile URLs, which article:author often holds.
+func metaAuthor(meta map[string]string) string {
+	for _, k := range metaKeys.author {
+		v := meta[k]
+		if v == "" || strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+			continue
+		}
+		return strings.TrimPrefix(v, "@")
+	}
+	return ""
+}
+
+var metaTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+func parseMetaTime(v string) *time.Time {
+	if v == "" {
+		return nil
+	}
+	for _, layout := range metaTimeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+// resolve makes ref absolute against base, keeping only http(s) URLs.
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
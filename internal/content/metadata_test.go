@@ -0,0 +1,62 @@
+package content
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/postgres-17")
+	m := ExtractMetadata(fixture(t, "article.html"), base)
+
+	assert.Equal(t, "Incremental backups, faster vacuum & JSON_TABLE.", m.Description)
+	assert.Equal(t, "https://example.com/images/pg17-card.png", m.Image)
+	assert.Equal(t, "Example Blog", m.SiteName)
+	assert.Equal(t, "Jane Doe", m.Author) // article:author is a profile URL
+	require.NotNil(t, m.PublishedAt)
+	assert.Equal(t, time.Date(2024, 9, 26, 10, 0, 0, 0, time.UTC), *m.PublishedAt)
+	assert.Equal(t, "https://example.com/icons/favicon-32.png", m.Favicon)
+}
+
+func TestExtractMetadata_Fallbacks(t *testing.T) {
+	base, _ := url.Parse("https://news.example.org/story")
+	doc := `<html><head>
+		<meta name="description" content="Plain description">
+		<meta name="twitter:description" content="Card description">
+		<meta name="twitter:image" content="https://cdn.example.org/card.jpg">
+		<meta name="twitter:creator" content="@reporter">
+		<meta name="date" content="2024-03-01">
+		<meta property="og:image" content="javascript:alert(1)">
+		</head><body><meta property="og:site_name" content="Ignored"></body></html>`
+	m := ExtractMetadata([]byte(doc), base)
+
+	assert.Equal(t, "Card description", m.Description)
+	assert.Equal(t, "https://cdn.example.org/card.jpg", m.Image) // og:image is not http(s)
+	assert.Empty(t, m.SiteName)
+	assert.Equal(t, "reporter", m.Author)
+	require.NotNil(t, m.PublishedAt)
+	assert.Equal(t, "2024-03-01", m.PublishedAt.Format("2006-01-02"))
+	assert.Equal(t, "https://news.example.org/favicon.ico", m.Favicon)
+}
+
+func TestExtractMetadata_TruncatesDescription(t *testing.T) {
+	long := strings.Repeat("word ", 200)
+	m := ExtractMetadata([]byte(`<meta property="og:description" content="`+long+`">`), nil)
+	assert.LessOrEqual(t, len(m.Description), maxDescriptionLen+len("…"))
+	assert.True(t, strings.HasSuffix(m.Description, "…"))
+	assert.Empty(t, m.Favicon)
+}
+
+func TestTruncateWords_KeepsMultiByteCharactersWhole(t *testing.T) {
+	got := truncateWords(strings.Repeat("é", 10), 5)
+	assert.Equal(t, "ééééé…", got)
+	assert.True(t, utf8.ValidString(got))
+
+	assert.Equal(t, "日本語…", truncateWords("日本語 テキストです", 6))
+}
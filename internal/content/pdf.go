@@ -0,0 +1,147 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	MaxPDFBytes = 20 * 1024 * 1024
+	MaxPDFPages = 200
+)
+
+// extractPDF returns the title and plain text of a PDF, one paragraph per
+// block separated by blank lines. The title comes from the document
+// metadata, falling back to the first line of text.
+func extractPDF(data []byte) (title, text string, err error) {
+	// The parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", "", err
+	}
+
+	var paragraphs []string
+	for i := 1; i <= r.NumPage() && i <= MaxPDFPages; i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		paragraphs = append(paragraphs, pdfParagraphs(page.Content().Text)...)
+	}
+	if len(paragraphs) == 0 {
+		return "", "", fmt.Errorf("no text in PDF")
+	}
+
+	title = strings.TrimSpace(r.Trailer().Key("Info").Key("Title").Text())
+	if !usefulPDFTitle(title) {
+		title, _, _ = strings.Cut(paragraphs[0], "\n")
+		title = truncateWords(title, maxPDFTitleLen)
+	}
+	return title, strings.Join(paragraphs, "\n\n"), nil
+}
+
+// maxPDFTitleLen caps, in characters, a title taken from the first line of text.
+const maxPDFTitleLen = 200
+
+// usefulPDFTitle rejects empty and tool-generated metadata titles.
+func usefulPDFTitle(title string) bool {
+	lower := strings.ToLower(title)
+	return title != "" && lower != "untitled" && !strings.HasPrefix(lower, "microsoft word - ") &&
+		!strings.HasSuffix(lower, ".dvi") && !strings.HasSuffix(lower, ".pdf") && !strings.HasSuffix(lower, ".docx")
+}
+
+// pdfParagraphs joins glyphs into lines and lines into paragraphs, in
+// content stream order. A vertical gap wider than a line or a change of font
+// size starts a new paragraph; words hyphenated across lines are rejoined.
+func pdfParagraphs(glyphs []pdf.Text) []string {
+	var paragraphs []string
+	var para, line strings.Builder
+	var lineY, lineSize, prevEnd float64
+	started := false
+
+	endLine := func() {
+		text := strings.TrimSpace(line.String())
+		line.Reset()
+		if text == "" {
+			return
+		}
+		current := para.String()
+		switch {
+		case current == "":
+		case strings.HasSuffix(current, "-") && startsLower(text):
+			para.Reset()
+			para.WriteString(strings.TrimSuffix(current, "-"))
+		default:
+			para.WriteByte(' ')
+		}
+		para.WriteString(text)
+	}
+	endParagraph := func() {
+		endLine()
+		if text := strings.TrimSpace(para.String()); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		para.Reset()
+	}
+
+	for _, g := range glyphs {
+		size := math.Abs(g.FontSize)
+		if size == 0 {
+			size = 10
+		}
+
+		if started && math.Abs(g.Y-lineY) > size/2 {
+			gap := lineY - g.Y
+			if gap > 1.6*lineSize || gap < 0 || math.Abs(size-lineSize) > 1 {
+				endParagraph()
+			} else {
+				endLine()
+			}
+		} else if started && g.X > prevEnd+0.2*size && g.S != " " && !strings.HasSuffix(line.String(), " ") {
+			line.WriteByte(' ')
+		}
+
+		if !started || line.Len() == 0 {
+			lineY, lineSize = g.Y, size
+		}
+		started = true
+		line.WriteString(g.S)
+		prevEnd = g.X + g.W
+	}
+	endParagraph()
+	return paragraphs
+}
+
+func startsLower(s string) bool {
+	for _, r := range s {
+		return unicode.IsLower(r)
+	}
+	return false
+}
+
+// pdfHTML renders extracted paragraphs as HTML for reading mode.
+func pdfHTML(title, text string) string {
+	var b strings.Builder
+	b.WriteString("<article><h1>")
+	b.WriteString(html.EscapeString(title))
+	b.WriteString("</h1>")
+	for _, p := range strings.Split(text, "\n\n") {
+		b.WriteString("<p>")
+		b.WriteString(html.EscapeString(p))
+		b.WriteString("</p>")
+	}
+	b.WriteString("</article>")
+	return b.String()
+}
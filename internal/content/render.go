@@ -0,0 +1,475 @@
+package content
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates.
+const WordsPerMinute = 230
+
+// Markdown converts extracted article HTML to Markdown, keeping headings,
+// lists, code blocks, quotes, tables, emphasis, links and images. Relative
+// URLs are resolved against baseURL.
+func Markdown(doc, baseURL string) string {
+	return render(doc, baseURL, true)
+}
+
+// PlainText converts extracted article HTML to readable plain text: one
+// paragraph per block, list markers kept, markup and URLs dropped.
+func PlainText(doc string) string {
+	return render(doc, "", false)
+}
+
+// WordCount counts whitespace-separated words.
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// ReadingMinutes estimates reading time, rounded up to whole minutes.
+func ReadingMinutes(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / WordsPerMinute))
+}
+
+// converter renders a parsed HTML tree as Markdown (md) or plain text.
+type converter struct {
+	md   bool
+	base *url.URL
+}
+
+func render(doc, baseURL string, md bool) string {
+	c := &converter{md: md}
+	if base, err := url.Parse(baseURL); err == nil && base.IsAbs() {
+		c.base = base
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(doc), context)
+	if err != nil {
+		return ""
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	return strings.Join(c.blocks(root), "\n\n")
+}
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true, "main": true,
+	"aside": true, "nav": true, "figure": true, "figcaption": true, "dl": true, "dt": true, "dd": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "pre": true, "blockquote": true, "table": true, "hr": true,
+}
+
+// blocks renders the children of n as a list of blocks. Runs of inline
+// content between block elements become paragraphs.
+func (c *converter) blocks(n *html.Node) []string {
+	var out []string
+	var para strings.Builder
+	flush := func() {
+		if text := c.paragraph(para.String()); text != "" {
+			out = append(out, text)
+		}
+		para.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		tag := elementName(child)
+		if child.Type != html.ElementNode || !blockElements[tag] {
+			para.WriteString(c.inline(child))
+			continue
+		}
+		flush()
+		if block := c.block(child, tag); block != "" {
+			out = append(out, block)
+		}
+	}
+	flush()
+	return out
+}
+
+func (c *converter) block(n *html.Node, tag string) string {
+	switch tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := tidyParagraph(c.inlineChildren(n))
+		if text == "" || !c.md {
+			return text
+		}
+		return strings.Repeat("#", int(tag[1]-'0')) + " " + strings.ReplaceAll(text, "\n", " ")
+	case "p", "dt", "figcaption":
+		return c.paragraph(c.inlineChildren(n))
+	case "pre":
+		return c.codeBlock(n)
+	case "ul", "ol":
+		return c.list(n, tag == "ol")
+	case "blockquote":
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if !c.md || inner == "" {
+			return inner
+		}
+		return prefixLines(inner, "> ", "> ")
+	case "table":
+		return c.table(n)
+	case "hr":
+		if c.md {
+			return "---"
+		}
+		return ""
+	default:
+		return strings.Join(c.blocks(n), "\n\n")
+	}
+}
+
+func (c *converter) codeBlock(n *html.Node) string {
+	code := strings.Trim(textContent(n), "\n")
+	if code == "" {
+		return ""
+	}
+	if !c.md {
+		return code
+	}
+
+	lang := codeLanguage(n)
+	fence := "```"
+	if strings.Contains(code, fence) {
+		fence = "~~~"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// codeLanguage reads a language-* or lang-* class from a pre or its code child.
+func codeLanguage(pre *html.Node) string {
+	candidates := []*html.Node{pre}
+	if child := pre.FirstChild; child != nil && elementName(child) == "code" {
+		candidates = append(candidates, child)
+	}
+	for _, n := range candidates {
+		for _, a := range n.Attr {
+			if a.Key != "class" {
+				continue
+			}
+			for _, class := range strings.Fields(a.Val) {
+				for _, prefix := range []string{"language-", "lang-"} {
+					if lang, ok := strings.CutPrefix(class, prefix); ok && isWord(lang) {
+						return lang
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (c *converter) list(n *html.Node, ordered bool) string {
+	var items []string
+	index := 1
+	if ordered {
+		for _, a := range n.Attr {
+			if a.Key == "start" {
+				if start, err := strconv.Atoi(a.Val); err == nil {
+					index = start
+				}
+			}
+		}
+	}
+
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if elementName(li) != "li" {
+			continue
+		}
+		body := strings.Join(c.blocks(li), "\n")
+		if body == "" {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		items = append(items, prefixLines(body, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *converter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch elementName(child) {
+			case "thead", "tbody", "tfoot":
+				walk(child)
+			case "tr":
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if tag := elementName(cell); tag == "td" || tag == "th" {
+						text := strings.ReplaceAll(tidyParagraph(c.inlineChildren(cell)), "\n", " ")
+						if c.md {
+							text = strings.ReplaceAll(text, "|", `\|`)
+						}
+						cells = append(cells, text)
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	if !c.md {
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			lines[i] = strings.Join(row, "\t")
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// Markdown tables need a header row and equal column counts
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *converter) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.inline(child))
+	}
+	return b.String()
+}
+
+func (c *converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if c.md {
+			text = escapeMarkdown(text)
+		}
+		return text
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	tag := elementName(n)
+	if droppedElements[tag] {
+		return ""
+	}
+	switch tag {
+	case "br":
+		return "\n"
+	case "img":
+		if !c.md {
+			return ""
+		}
+		src, ok := absoluteURL(attr(n, "src"), c.base, false)
+		if !ok {
+			return ""
+		}
+		return "![" + escapeMarkdown(collapseSpace(attr(n, "alt"))) + "](" + linkTarget(src) + ")"
+	case "code", "kbd", "samp":
+		code := collapseSpace(textContent(n))
+		if !c.md || strings.TrimSpace(code) == "" {
+			return code
+		}
+		return wrapInline(code, "`")
+	}
+
+	inner := c.inlineChildren(n)
+	if !c.md {
+		return inner
+	}
+	switch tag {
+	case "a":
+		href, ok := absoluteURL(attr(n, "href"), c.base, true)
+		text := strings.TrimSpace(inner)
+		if !ok || text == "" {
+			return inner
+		}
+		return "[" + text + "](" + linkTarget(href) + ")"
+	case "em", "i", "cite":
+		return wrapInline(inner, "*")
+	case "strong", "b":
+		return wrapInline(inner, "**")
+	case "del", "s":
+		return wrapInline(inner, "~~")
+	default:
+		return inner
+	}
+}
+
+// wrapInline surrounds the trimmed text with a marker, keeping outer spaces.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// linkTarget escapes characters that would end a Markdown link destination.
+func linkTarget(u string) string {
+	return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(u)
+}
+
+// markdownEscaper escapes text so it renders literally. Angle brackets and
+// ampersands become entities: the HTML parser has already decoded them, and
+// renderers that allow inline HTML would otherwise run it.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+	"<", "&lt;", ">", "&gt;", "&", "&amp;")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// paragraph tidies inline content into a paragraph. In Markdown, lines that
+// would start a heading, list or thematic break are escaped (quotes can't
+// start: > is already an entity); markup the converter generates never
+// starts with these characters.
+func (c *converter) paragraph(inline string) string {
+	text := tidyParagraph(inline)
+	if !c.md || text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = escapeLineStart(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escapeLineStart(line string) string {
+	if line == "" {
+		return line
+	}
+	if strings.IndexByte("#-+=", line[0]) >= 0 {
+		return `\` + line
+	}
+	// Ordered list markers: digits followed by . or )
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return line
+}
+
+// collapseSpace replaces runs of whitespace with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// tidyParagraph trims each line of a paragraph and drops blank lines.
+func tidyParagraph(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// prefixLines puts first before the first line and rest before the others.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && elementName(child) == "br" {
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func elementName(n *html.Node) string {
+	if n.Type != html.ElementNode {
+		return ""
+	}
+	return strings.ToLower(n.Data)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '+' || r == '#') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,74 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const articleHTML = `<div class="page">
+<h1>Release   notes</h1>
+<p>We shipped <strong>fast</strong> builds and <a href="/docs/start">a guide</a>.<br>Second line.</p>
+<script>track()</script>
+<ul>
+  <li>First <em>item</em></li>
+  <li>Second
+    <ol><li>Nested one</li><li>Nested two</li></ol>
+  </li>
+</ul>
+<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}</code></pre>
+<blockquote><p>Quoted text</p></blockquote>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a_b</td><td>1</td></tr></table>
+<p><img src="img/chart.png" alt="Chart"> Use <code>go test</code> (fast)</p>
+</div>`
+
+func TestMarkdown(t *testing.T) {
+	want := "# Release notes\n\n" +
+		"We shipped **fast** builds and [a guide](https://example.com/docs/start).\nSecond line.\n\n" +
+		"- First *item*\n- Second\n  1. Nested one\n  2. Nested two\n\n" +
+		"```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n\n" +
+		"> Quoted text\n\n" +
+		"| Name | Value |\n| --- | --- |\n| a\\_b | 1 |\n\n" +
+		"![Chart](https://example.com/blog/img/chart.png) Use `go test` (fast)"
+	assert.Equal(t, want, Markdown(articleHTML, "https://example.com/blog/post"))
+}
+
+func TestPlainText(t *testing.T) {
+	want := "Release notes\n\n" +
+		"We shipped fast builds and a guide.\nSecond line.\n\n" +
+		"- First item\n- Second\n  1. Nested one\n  2. Nested two\n\n" +
+		"func main() {\n\tfmt.Println(\"hi\")\n}\n\n" +
+		"Quoted text\n\n" +
+		"Name\tValue\na_b\t1\n\n" +
+		"Use go test (fast)"
+	assert.Equal(t, want, PlainText(articleHTML))
+}
+
+func TestMarkdown_LinkTargetsEscaped(t *testing.T) {
+	assert.Equal(t, "[wiki](https://en.wikipedia.org/wiki/Go_%28game%29)",
+		Markdown(`<a href="https://en.wikipedia.org/wiki/Go_(game)">wiki</a>`, ""))
+	assert.Equal(t, `javascript link`, Markdown(`<a href="javascript:alert(1)">javascript link</a>`, ""))
+}
+
+func TestMarkdown_TextCannotInjectMarkup(t *testing.T) {
+	doc := `<p>&lt;img src=x onerror=alert(1)&gt; &amp; AT&amp;T</p>` +
+		`<p># not a heading<br>- not a list<br>1999. not a list either<br>&gt; not a quote</p>` +
+		`<div>+ plain</div>`
+	want := "&lt;img src=x onerror=alert(1)&gt; &amp; AT&amp;T\n\n" +
+		"\\# not a heading\n\\- not a list\n1999\\. not a list either\n&gt; not a quote\n\n" +
+		"\\+ plain"
+	assert.Equal(t, want, Markdown(doc, ""))
+
+	// Plain text is unchanged
+	assert.Equal(t, "<img src=x onerror=alert(1)> & AT&T", PlainText(`&lt;img src=x onerror=alert(1)&gt; &amp; AT&amp;T`))
+}
+
+func TestReadingStats(t *testing.T) {
+	assert.Equal(t, 3, WordCount("  one two\nthree "))
+	assert.Equal(t, 0, ReadingMinutes(0))
+	assert.Equal(t, 1, ReadingMinutes(1))
+	assert.Equal(t, 1, ReadingMinutes(WordsPerMinute))
+	assert.Equal(t, 2, ReadingMinutes(WordsPerMinute+1))
+}
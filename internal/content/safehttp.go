@@ -0,0 +1,138 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	MaxRedirects = 5
+	MaxBodyBytes = 2 * 1024 * 1024
+)
+
+var (
+	ErrBlockedURL     = errors.New("url not allowed")
+	ErrBlockedAddress = errors.New("address not allowed")
+	ErrTooLarge       = errors.New("response too large")
+	ErrContentType    = errors.New("unsupported content type")
+)
+
+// allowedPorts are the ports outbound fetches may connect to.
+var allowedPorts = map[string]bool{"80": true, "443": true, "8000": true, "8080": true, "8443": true}
+
+// blockedPrefixes covers ranges not caught by the netip.Addr predicates:
+// shared, benchmarking, reserved and translation address space.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fec0::/10"),
+}
+
+// isBlockedAddr reports whether an address is internal or otherwise not a
+// public unicast destination.
+func isBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return true // Loopback, link-local, multicast and unspecified are not global unicast
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL validates the scheme, port and (for IP literals) host of an
+// outbound URL. Hostnames are checked again after resolution at dial time.
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrBlockedURL, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrBlockedURL)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrBlockedURL)
+	}
+	if port := u.Port(); port != "" && !allowedPorts[port] {
+		return fmt.Errorf("%w: port %s", ErrBlockedURL, port)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && isBlockedAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// dialControl runs after DNS resolution for every connection attempt, so a
+// hostname that resolves (or rebinds) to an internal address is refused.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if isBlockedAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	if !allowedPorts[strconv.Itoa(int(addrPort.Port()))] {
+		return fmt.Errorf("%w: port %d", ErrBlockedAddress, addrPort.Port())
+	}
+	return nil
+}
+
+// checkRedirect validates every redirect hop and caps the chain length.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", MaxRedirects)
+	}
+	return CheckURL(req.URL)
+}
+
+// NewSafeTransport returns a transport for fetching user-supplied URLs. It
+// ignores proxy settings and only connects to public addresses on allowed
+// ports.
+func NewSafeTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}
+	return &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	}
+}
+
+// checkContentType verifies that a Content-Type header names one of the
+// allowed media types and returns the media type.
+func checkContentType(contentType string, allowedTypes []string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrContentType, contentType)
+	}
+	if !slices.Contains(allowedTypes, mediaType) {
+		return "", fmt.Errorf("%w: %s", ErrContentType, mediaType)
+	}
+	return mediaType, nil
+}
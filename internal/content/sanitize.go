@@ -0,0 +1,171 @@
+package content
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HNBaseURL resolves relative links in Hacker News comment HTML.
+const HNBaseURL = "https://news.ycombinator.com/"
+
+// allowedElements maps each permitted element to its permitted attributes.
+// Elements not listed are unwrapped (children kept, tag dropped).
+var allowedElements = map[string][]string{
+	"a": {"href", "title"}, "img": {"src", "alt", "title", "width", "height"},
+	"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"blockquote": {"cite"}, "q": {"cite"}, "pre": nil, "code": nil, "kbd": nil, "samp": nil, "var": nil,
+	"em": nil, "strong": nil, "b": nil, "i": nil, "u": nil, "s": nil, "del": nil, "ins": nil,
+	"sub": nil, "sup": nil, "small": nil, "mark": nil, "abbr": {"title"}, "cite": nil, "time": {"datetime"},
+	"ul": nil, "ol": {"start"}, "li": nil, "dl": nil, "dt": nil, "dd": nil,
+	"table": nil, "caption": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil,
+	"th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+	"figure": nil, "figcaption": nil, "section": nil, "article": nil, "header": nil, "footer": nil,
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "noscript": true, "template": true, "svg": true, "math": true,
+	"form": true, "input": true, "button": true, "select": true, "textarea": true, "title": true,
+	"head": true, "meta": true, "link": true, "base": true,
+}
+
+// urlAttributes are resolved against the base URL and checked by scheme.
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true}
+
+// Sanitize returns an allowlisted rendition of an HTML document or fragment.
+// Scripts, event handlers, styles and embedded frames are removed, relative
+// URLs are made absolute against baseURL, and links open with rel=noopener.
+// URLs that cannot be made absolute are dropped.
+func Sanitize(doc, baseURL string) string {
+	base, err := url.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(doc), context)
+	if err != nil {
+		return html.EscapeString(doc)
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		renderSanitized(&b, n, base)
+	}
+	return b.String()
+}
+
+func renderSanitized(b *strings.Builder, n *html.Node, base *url.URL) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return // Comments, doctypes
+	}
+
+	tag := strings.ToLower(n.Data)
+	if droppedElements[tag] {
+		return
+	}
+	allowedAttrs, ok := allowedElements[tag]
+	if !ok || n.Namespace != "" {
+		renderChildren(b, n, base)
+		return
+	}
+
+	attrs := sanitizeAttrs(tag, n.Attr, allowedAttrs, base)
+	if tag == "img" && !hasAttr(attrs, "src") {
+		return
+	}
+	if tag == "a" && hasAttr(attrs, "href") {
+		attrs = append(attrs, html.Attribute{Key: "target", Val: "_blank"}, html.Attribute{Key: "rel", Val: "noopener noreferrer"})
+	}
+
+	b.WriteByte('<')
+	b.WriteString(tag)
+	for _, a := range attrs {
+		b.WriteByte(' ')
+		b.WriteString(a.Key)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(a.Val))
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+
+	if isVoidElement(tag) {
+		return
+	}
+	renderChildren(b, n, base)
+	b.WriteString("</")
+	b.WriteString(tag)
+	b.WriteByte('>')
+}
+
+func renderChildren(b *strings.Builder, n *html.Node, base *url.URL) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderSanitized(b, c, base)
+	}
+}
+
+func sanitizeAttrs(tag string, attrs []html.Attribute, allowed []string, base *url.URL) []html.Attribute {
+	var out []html.Attribute
+	for _, a := range attrs {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || !slices.Contains(allowed, key) {
+			continue
+		}
+		val := a.Val
+		if urlAttributes[key] {
+			var ok bool
+			if val, ok = absoluteURL(val, base, tag == "a"); !ok {
+				continue
+			}
+		}
+		out = append(out, html.Attribute{Key: key, Val: val})
+	}
+	return out
+}
+
+// absoluteURL resolves ref against base and allows only http(s), plus
+// mailto for links.
+func absoluteURL(ref string, base *url.URL, isLink bool) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", false
+	}
+	if !u.IsAbs() {
+		if base == nil {
+			return "", false
+		}
+		u = base.ResolveReference(u)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.String(), true
+	case "mailto":
+		return u.String(), isLink
+	default:
+		return "", false
+	}
+}
+
+func isVoidElement(tag string) bool {
+	return tag == "br" || tag == "hr" || tag == "img"
+}
+
+func hasAttr(attrs []html.Attribute, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
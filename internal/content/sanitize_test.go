@@ -0,0 +1,45 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	base := "https://example.com/blog/post.html"
+	cases := []struct {
+		name, in, want string
+	}{
+		{"script removed", `<p>hi<script>alert(1)</script></p>`, `<p>hi</p>`},
+		{"event handler removed", `<img src="a.png" onerror="alert(1)">`, `<img src="https://example.com/blog/a.png">`},
+		{"iframe removed", `<iframe src="https://evil.example"></iframe><p>x</p>`, `<p>x</p>`},
+		{"javascript url dropped", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"obfuscated javascript url dropped", `<a href=" JaVaScRiPt:alert(1)">x</a>`, `<a>x</a>`},
+		{"data image dropped", `<img src="data:image/svg+xml,<svg onload=alert(1)>">`, ``},
+		{"relative link absolute", `<a href="../about">about</a>`, `<a href="https://example.com/about" target="_blank" rel="noopener noreferrer">about</a>`},
+		{"rel and target replaced", `<a href="/x" target="_self" rel="opener">x</a>`, `<a href="https://example.com/x" target="_blank" rel="noopener noreferrer">x</a>`},
+		{"mailto allowed on links", `<a href="mailto:a@example.com">mail</a>`, `<a href="mailto:a@example.com" target="_blank" rel="noopener noreferrer">mail</a>`},
+		{"style and class stripped", `<p style="x" class="y">t</p>`, `<p>t</p>`},
+		{"unknown tags unwrapped", `<custom-el><b>bold</b></custom-el>`, `<b>bold</b>`},
+		{"svg dropped", `<svg><script>alert(1)</script></svg>ok`, `ok`},
+		{"text escaped", `5 &lt; 6 &amp; "q"`, `5 &lt; 6 &amp; &#34;q&#34;`},
+		{"unbalanced markup closed", `<p><i>open`, `<p><i>open</i></p>`},
+		{"full document", `<html><head><title>T</title><style>p{}</style></head><body><p>body</p></body></html>`, `<p>body</p>`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Sanitize(tc.in, base))
+		})
+	}
+}
+
+func TestSanitize_HNComment(t *testing.T) {
+	in := `See <a href="item?id=123" rel="nofollow">this</a><p><pre><code>  x := 1</code></pre>`
+	want := `See <a href="https://news.ycombinator.com/item?id=123" target="_blank" rel="noopener noreferrer">this</a><p></p><pre><code>  x := 1</code></pre>`
+	assert.Equal(t, want, Sanitize(in, HNBaseURL))
+}
+
+func TestSanitize_NoBaseDropsRelativeURLs(t *testing.T) {
+	assert.Equal(t, `<a>x</a>`, Sanitize(`<a href="/x">x</a>`, ""))
+}
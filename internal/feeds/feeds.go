@@ -0,0 +1,299 @@
+// Package feeds renders lists of items as RSS 2.0, Atom 1.0 and JSON Feed 1.1.
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Feed is a format-independent feed.
+type Feed struct {
+	Title       string
+	Link        string // HTML page the feed describes
+	FeedURL     string // URL the feed itself is served from
+	Description string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is one feed entry. ContentHTML is optional.
+type Item struct {
+	ID          string // Stable, unique permalink
+	Title       string
+	Link        string
+	CommentsURL string
+	Author      string
+	Published   time.Time
+	Summary     string
+	ContentHTML string
+	Categories  []string
+}
+
+// Formats maps URL extensions to writers.
+var Formats = map[string]func(io.Writer, *Feed) error{
+	"rss":  WriteRSS,
+	"atom": WriteAtom,
+	"json": WriteJSON,
+}
+
+// ContentTypes maps URL extensions to response content types.
+var ContentTypes = map[string]string{
+	"rss":  "application/rss+xml; charset=utf-8",
+	"atom": "application/atom+xml; charset=utf-8",
+	"json": "application/feed+json; charset=utf-8",
+}
+
+// ─── RSS 2.0 ───
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          rssSelf   `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Comments    string   `xml:"comments,omitempty"`
+	Author      string   `xml:"dc:creator,omitempty"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes the feed as RSS 2.0. Item descriptions carry ContentHTML
+// when set, otherwise Summary.
+func WriteRSS(w io.Writer, f *Feed) error {
+	doc := rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Self:        rssSelf{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Description: f.Description,
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, it := range f.Items {
+		desc := it.ContentHTML
+		if desc == "" {
+			desc = it.Summary
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: it.ID},
+			Comments:    it.CommentsURL,
+			Author:      it.Author,
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+			Description: desc,
+			Categories:  it.Categories,
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// ─── Atom 1.0 ───
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	ID       string      `xml:"id"`
+	Links    []atomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom writes the feed as Atom 1.0.
+func WriteAtom(w io.Writer, f *Feed) error {
+	updated := f.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomFeed{
+		Title:    f.Title,
+		ID:       f.FeedURL,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Subtitle: f.Description,
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, it := range f.Items {
+		e := atomEntry{
+			Title:     it.Title,
+			ID:        it.ID,
+			Links:     []atomLink{{Href: it.Link, Rel: "alternate"}},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Published.UTC().Format(time.RFC3339),
+		}
+		if it.CommentsURL != "" && it.CommentsURL != it.Link {
+			e.Links = append(e.Links, atomLink{Href: it.CommentsURL, Rel: "replies", Type: "text/html"})
+		}
+		if it.Author != "" {
+			e.Author = &atomAuthor{Name: it.Author}
+		}
+		if it.Summary != "" {
+			e.Summary = &atomText{Type: "text", Value: it.Summary}
+		}
+		if it.ContentHTML != "" {
+			e.Content = &atomText{Type: "html", Value: it.ContentHTML}
+		}
+		for _, c := range it.Categories {
+			e.Categories = append(e.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	return writeXML(w, doc)
+}
+
+// ─── JSON Feed 1.1 ───
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url,omitempty"`
+	ExternalURL   string       `json:"external_url,omitempty"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html,omitempty"`
+	ContentText   string       `json:"content_text,omitempty"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+// WriteJSON writes the feed as JSON Feed 1.1. The item url is the discussion
+// and external_url the linked article, matching how link blogs use the format.
+func WriteJSON(w io.Writer, f *Feed) error {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+
+	for _, it := range f.Items {
+		ji := jsonItem{
+			ID:            it.ID,
+			URL:           it.CommentsURL,
+			Title:         it.Title,
+			ContentHTML:   it.ContentHTML,
+			Summary:       it.Summary,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			Tags:          it.Categories,
+		}
+		if ji.URL == "" {
+			ji.URL = it.Link
+		} else if it.Link != it.CommentsURL {
+			ji.ExternalURL = it.Link
+		}
+		// Either content_html or content_text is required
+		if ji.ContentHTML == "" {
+			ji.ContentText = it.Summary
+		}
+		if it.Author != "" {
+			ji.Authors = []jsonAuthor{{Name: it.Author}}
+		}
+		doc.Items = append(doc.Items, ji)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// writeXML writes the XML declaration followed by the document.
+func writeXML(w io.Writer, doc interface{}) error {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
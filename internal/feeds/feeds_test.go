@@ -0,0 +1,88 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleFeed() *Feed {
+	return &Feed{
+		Title:   "HN Station",
+		Link:    "https://hnstation.dev/",
+		FeedURL: "https://hnstation.dev/feeds/stories.rss",
+		Updated: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Items: []Item{{
+			ID:          "https://news.ycombinator.com/item?id=1",
+			Title:       "Postgres & <friends>",
+			Link:        "https://example.com/post",
+			CommentsURL: "https://news.ycombinator.com/item?id=1",
+			Author:      "pg",
+			Published:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			Summary:     "- point one",
+			ContentHTML: "<p>point one</p>",
+			Categories:  []string{"databases"},
+		}},
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteRSS(&buf, sampleFeed()))
+
+	var doc struct {
+		Channel struct {
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				Description string `xml:"description"`
+				PubDate     string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(t, doc.Channel.Items, 1)
+	assert.Equal(t, "Postgres & <friends>", doc.Channel.Items[0].Title)
+	assert.Equal(t, "<p>point one</p>", doc.Channel.Items[0].Description)
+	assert.Equal(t, "Wed, 01 May 2024 10:00:00 +0000", doc.Channel.Items[0].PubDate)
+	assert.Contains(t, buf.String(), `xmlns:dc="http://purl.org/dc/elements/1.1/"`)
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteAtom(&buf, sampleFeed()))
+
+	var doc struct {
+		Entries []struct {
+			ID      string `xml:"id"`
+			Summary string `xml:"summary"`
+			Links   []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(t, doc.Entries, 1)
+	assert.Equal(t, "- point one", doc.Entries[0].Summary)
+	assert.Len(t, doc.Entries[0].Links, 2)
+	assert.Equal(t, "replies", doc.Entries[0].Links[1].Rel)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, sampleFeed()))
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+
+	item := doc["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "https://news.ycombinator.com/item?id=1", item["url"])
+	assert.Equal(t, "https://example.com/post", item["external_url"])
+	assert.Equal(t, "<p>point one</p>", item["content_html"])
+}
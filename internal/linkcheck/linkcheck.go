@@ -0,0 +1,96 @@
+// Package linkcheck tracks whether story URLs still resolve, so reading
+// mode can go straight to an archived copy once a page is gone.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+// A link is considered dead after GoneAfter consecutive checks answered
+// with a definitive status such as 404, or DeadAfter consecutive failures
+// of any kind. One 404 is not enough: sites briefly break during deploys.
+const (
+	GoneAfter = 2
+	DeadAfter = 3
+)
+
+// Prober requests a URL and returns the response status; *content.Fetcher
+// implements it.
+type Prober interface {
+	Check(ctx context.Context, url string) (int, error)
+}
+
+// Store is the subset of storage.Store used by the checker.
+type Store interface {
+	GetLinkCheck(ctx context.Context, storyID int64) (*storage.LinkCheck, error)
+	SaveLinkCheck(ctx context.Context, c *storage.LinkCheck) error
+}
+
+type Checker struct {
+	store  Store
+	prober Prober
+}
+
+func New(store Store, prober Prober) *Checker {
+	return &Checker{store: store, prober: prober}
+}
+
+// Check probes a story's URL and records the outcome. Cancellation is not
+// recorded.
+func (c *Checker) Check(ctx context.Context, storyID int64, url string) (*storage.LinkCheck, error) {
+	prev, err := c.store.GetLinkCheck(ctx, storyID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	status, probeErr := c.prober.Check(ctx, url)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	check := &storage.LinkCheck{StoryID: storyID, URL: url}
+	if status != 0 {
+		check.HTTPStatus = &status
+	}
+	failures := 0
+	if prev != nil && prev.URL == url {
+		failures = prev.Failures
+	}
+	check.Status, check.Failures = classify(status, probeErr, failures)
+	switch {
+	case probeErr != nil:
+		msg := probeErr.Error()
+		check.Error = &msg
+	case check.Status != storage.LinkStatusOK:
+		msg := fmt.Sprintf("HTTP %d", status)
+		check.Error = &msg
+	}
+
+	if err := c.store.SaveLinkCheck(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// classify returns the link status and the new count of consecutive
+// failures. Statuses that say the page is gone make it dead after GoneAfter
+// checks in a row; errors that may be transient only after DeadAfter.
+// Pages that refuse bots or ask for a login still exist.
+func classify(status int, err error, failures int) (string, int) {
+	gone := err == nil && (status == http.StatusNotFound || status == http.StatusGone || status == http.StatusUnavailableForLegalReasons)
+	switch {
+	case gone && failures+1 >= GoneAfter:
+		return storage.LinkStatusDead, failures + 1
+	case err == nil && (status < 400 || status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests):
+		return storage.LinkStatusOK, 0
+	case failures+1 >= DeadAfter:
+		return storage.LinkStatusDead, failures + 1
+	default:
+		return storage.LinkStatusError, failures + 1
+	}
+}
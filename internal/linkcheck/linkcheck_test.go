@@ -0,0 +1,97 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memStore map[int64]storage.LinkCheck
+
+func (m memStore) GetLinkCheck(_ context.Context, storyID int64) (*storage.LinkCheck, error) {
+	c, ok := m[storyID]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (m memStore) SaveLinkCheck(_ context.Context, c *storage.LinkCheck) error {
+	m[c.StoryID] = *c
+	return nil
+}
+
+type prober struct {
+	status int
+	err    error
+}
+
+func (p *prober) Check(context.Context, string) (int, error) { return p.status, p.err }
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		status   int
+		err      error
+		failures int
+		want     string
+	}{
+		{http.StatusOK, nil, 2, storage.LinkStatusOK},
+		{http.StatusForbidden, nil, 0, storage.LinkStatusOK},
+		{http.StatusNotFound, nil, 0, storage.LinkStatusError},
+		{http.StatusNotFound, nil, 1, storage.LinkStatusDead},
+		{http.StatusGone, nil, 0, storage.LinkStatusError},
+		{http.StatusGone, nil, 1, storage.LinkStatusDead},
+		{http.StatusBadGateway, nil, 0, storage.LinkStatusError},
+		{0, errors.New("no such host"), 1, storage.LinkStatusError},
+		{0, errors.New("no such host"), 2, storage.LinkStatusDead},
+	}
+	for _, c := range cases {
+		got, _ := classify(c.status, c.err, c.failures)
+		assert.Equal(t, c.want, got, "status %d, err %v, failures %d", c.status, c.err, c.failures)
+	}
+}
+
+func TestCheck_DeadAfterRepeatedFailures(t *testing.T) {
+	store := memStore{}
+	p := &prober{err: errors.New("connection refused")}
+	checker := New(store, p)
+	ctx := context.Background()
+
+	for i := 1; i < DeadAfter; i++ {
+		check, err := checker.Check(ctx, 1, "https://example.com/a")
+		require.NoError(t, err)
+		assert.Equal(t, storage.LinkStatusError, check.Status)
+		assert.Equal(t, i, check.Failures)
+	}
+	check, err := checker.Check(ctx, 1, "https://example.com/a")
+	require.NoError(t, err)
+	assert.Equal(t, storage.LinkStatusDead, check.Status)
+	assert.Equal(t, "connection refused", *check.Error)
+
+	// A changed URL starts over, and a success resets the count
+	check, err = checker.Check(ctx, 1, "https://example.com/b")
+	require.NoError(t, err)
+	assert.Equal(t, storage.LinkStatusError, check.Status)
+
+	p.status, p.err = http.StatusOK, nil
+	check, err = checker.Check(ctx, 1, "https://example.com/b")
+	require.NoError(t, err)
+	assert.Equal(t, storage.LinkStatusOK, check.Status)
+	assert.Zero(t, check.Failures)
+	assert.Nil(t, check.Error)
+}
+
+func TestCheck_CancellationIsNotRecorded(t *testing.T) {
+	store := memStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(store, &prober{err: context.Canceled}).Check(ctx, 1, "https://example.com/a")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, store)
+}
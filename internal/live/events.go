@@ -0,0 +1,186 @@
+// Package live derives front-page change events from ingest snapshots and
+// fans them out to connected clients.
+package live
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/content"
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+)
+
+const (
+	EventNewStory    = "new_story"
+	EventStoryUpdate = "story_update"
+	EventNewComment  = "new_comment"
+	// EventIngestComplete follows the other events of a run; Version identifies the run.
+	EventIngestComplete = "ingest_complete"
+)
+
+// maxPayloadBytes keeps NOTIFY payloads under Postgres' 8000 byte limit.
+const maxPayloadBytes = 7000
+
+// Event is one live update. Only the fields relevant to the type are set.
+// Rank 0 means the story dropped off the front page.
+type Event struct {
+	Type          string    `json:"type"`
+	StoryID       int64     `json:"story_id,omitempty"`
+	CommentID     int64     `json:"comment_id,omitempty"`
+	ParentID      *int64    `json:"parent_id,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	By            string    `json:"by,omitempty"`
+	Text          string    `json:"text,omitempty"`
+	Rank          *int      `json:"rank,omitempty"`
+	PrevRank      *int      `json:"prev_rank,omitempty"`
+	Score         *int      `json:"score,omitempty"`
+	ScoreDelta    int       `json:"score_delta,omitempty"`
+	Descendants   *int      `json:"descendants,omitempty"`
+	CommentsDelta int       `json:"comments_delta,omitempty"`
+	PostedAt      time.Time `json:"posted_at,omitzero"`
+	Version       int64     `json:"version,omitempty"`
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func rankValue(r *int) int {
+	if r == nil {
+		return 0
+	}
+	return *r
+}
+
+// DiffStories compares snapshots taken before and after an ingest run.
+// Stories created at or after runStart are reported as new; others get a
+// story_update carrying only the fields that changed.
+func DiffStories(before, after map[int64]storage.StorySnapshot, runStart time.Time) []Event {
+	var events []Event
+
+	for id, cur := range after {
+		prev, seen := before[id]
+
+		if !seen && !cur.CreatedAt.Before(runStart) {
+			events = append(events, Event{
+				Type:        EventNewStory,
+				StoryID:     id,
+				Title:       cur.Title,
+				URL:         cur.URL,
+				By:          cur.By,
+				Rank:        intPtr(rankValue(cur.Rank)),
+				Score:       intPtr(cur.Score),
+				Descendants: intPtr(cur.Descendants),
+			})
+			continue
+		}
+
+		ev := Event{Type: EventStoryUpdate, StoryID: id}
+		changed := false
+		if rankValue(prev.Rank) != rankValue(cur.Rank) {
+			ev.Rank = intPtr(rankValue(cur.Rank))
+			ev.PrevRank = intPtr(rankValue(prev.Rank))
+			changed = true
+		}
+		if seen && prev.Score != cur.Score {
+			ev.Score = intPtr(cur.Score)
+			ev.ScoreDelta = cur.Score - prev.Score
+			changed = true
+		}
+		if seen && prev.Descendants != cur.Descendants {
+			ev.Descendants = intPtr(cur.Descendants)
+			ev.CommentsDelta = cur.Descendants - prev.Descendants
+			changed = true
+		}
+		if changed {
+			events = append(events, ev)
+		}
+	}
+
+	// Ranked stories that fell out of the snapshot window lost their rank
+	for id, prev := range before {
+		if _, ok := after[id]; !ok && prev.Rank != nil {
+			events = append(events, Event{Type: EventStoryUpdate, StoryID: id, Rank: intPtr(0), PrevRank: intPtr(*prev.Rank)})
+		}
+	}
+
+	// Front-page order first, then everything else by ID
+	sort.Slice(events, func(i, j int) bool {
+		ri, rj := rankValue(events[i].Rank), rankValue(events[j].Rank)
+		if (ri == 0) != (rj == 0) {
+			return ri != 0
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return events[i].StoryID < events[j].StoryID
+	})
+	return events
+}
+
+// CommentEvents converts newly ingested comments to events.
+func CommentEvents(comments []storage.LiveComment) []Event {
+	events := make([]Event, 0, len(comments))
+	for _, c := range comments {
+		events = append(events, Event{
+			Type:      EventNewComment,
+			StoryID:   c.StoryID,
+			CommentID: c.ID,
+			ParentID:  c.ParentID,
+			By:        c.By,
+			Text:      content.Sanitize(c.Text, content.HNBaseURL),
+			PostedAt:  c.PostedAt,
+		})
+	}
+	return events
+}
+
+// EncodePayloads packs events into JSON arrays small enough for NOTIFY.
+func EncodePayloads(events []Event) ([]string, error) {
+	var payloads []string
+	var batch []json.RawMessage
+	size := 2 // []
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		out, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, string(out))
+		batch, size = nil, 2
+		return nil
+	}
+
+	for _, ev := range events {
+		raw, err := json.Marshal(ev)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw)+2 > maxPayloadBytes {
+			continue // Cannot be delivered; comment text is truncated so this should not happen
+		}
+		if size+len(raw)+1 > maxPayloadBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		batch = append(batch, raw)
+		size += len(raw) + 1
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return payloads, nil
+}
+
+// DecodePayload parses a NOTIFY payload produced by EncodePayloads.
+func DecodePayload(payload string) ([]Event, error) {
+	var events []Event
+	err := json.Unmarshal([]byte(payload), &events)
+	return events, err
+}
@@ -0,0 +1,88 @@
+package live
+
+import (
+	"sync"
+)
+
+// subscriberBuffer is how many events a slow client may fall behind before
+// further events are dropped for it.
+const subscriberBuffer = 256
+
+// Subscriber receives events on C. Comment events are only delivered for the
+// stories it subscribed to; front-page events go to everyone.
+type Subscriber struct {
+	C       chan Event
+	stories map[int64]bool
+}
+
+// Hub fans events out to subscribers.
+type Hub struct {
+	mu        sync.RWMutex
+	subs      map[*Subscriber]struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscriber]struct{}), done: make(chan struct{})}
+}
+
+// Done is closed when the hub shuts down; streaming handlers should return.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Close signals all streaming handlers to finish, e.g. on server shutdown.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// Subscribe registers a subscriber interested in comments on storyIDs.
+func (h *Hub) Subscribe(storyIDs []int64) *Subscriber {
+	sub := &Subscriber{
+		C:       make(chan Event, subscriberBuffer),
+		stories: make(map[int64]bool, len(storyIDs)),
+	}
+	for _, id := range storyIDs {
+		sub.stories[id] = true
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes a subscriber. Its channel is not closed, so a
+// concurrent Publish can never send on a closed channel.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
+
+// Publish delivers events without blocking; a subscriber whose buffer is full
+// misses them.
+func (h *Hub) Publish(events []Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs {
+		for _, ev := range events {
+			if ev.Type == EventNewComment && !sub.stories[ev.StoryID] {
+				continue
+			}
+			select {
+			case sub.C <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of connected subscribers.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
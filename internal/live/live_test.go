@@ -0,0 +1,85 @@
+package live
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajeshkumarblr/hn_station/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func rank(v int) *int { return &v }
+
+func TestDiffStories(t *testing.T) {
+	runStart := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := runStart.Add(-time.Hour)
+
+	before := map[int64]storage.StorySnapshot{
+		1: {ID: 1, Score: 100, Descendants: 10, Rank: rank(1), CreatedAt: old},
+		2: {ID: 2, Score: 50, Descendants: 5, Rank: rank(2), CreatedAt: old},
+		3: {ID: 3, Score: 20, Descendants: 0, Rank: rank(3), CreatedAt: old},
+		4: {ID: 4, Score: 5, CreatedAt: old},
+	}
+	after := map[int64]storage.StorySnapshot{
+		1: {ID: 1, Score: 100, Descendants: 10, Rank: rank(2), CreatedAt: old},
+		2: {ID: 2, Score: 60, Descendants: 8, Rank: rank(1), CreatedAt: old},
+		4: {ID: 4, Score: 5, CreatedAt: old},
+		5: {ID: 5, Title: "New", Score: 1, CreatedAt: runStart.Add(time.Second)},
+	}
+
+	events := DiffStories(before, after, runStart)
+	assert.Len(t, events, 4)
+
+	// Ranked first: story 2 now #1, story 1 now #2
+	assert.Equal(t, int64(2), events[0].StoryID)
+	assert.Equal(t, 1, *events[0].Rank)
+	assert.Equal(t, 10, events[0].ScoreDelta)
+	assert.Equal(t, 3, events[0].CommentsDelta)
+
+	assert.Equal(t, int64(1), events[1].StoryID)
+	assert.Nil(t, events[1].Score)
+
+	// Story 3 left the snapshot and lost its rank; story 5 is new
+	assert.Equal(t, int64(3), events[2].StoryID)
+	assert.Equal(t, 0, *events[2].Rank)
+	assert.Equal(t, EventNewStory, events[3].Type)
+	assert.Equal(t, "New", events[3].Title)
+}
+
+func TestEncodePayloads(t *testing.T) {
+	var events []Event
+	for i := 0; i < 200; i++ {
+		events = append(events, Event{Type: EventNewComment, StoryID: 1, CommentID: int64(i), Text: strings.Repeat("x", 200)})
+	}
+
+	payloads, err := EncodePayloads(events)
+	assert.NoError(t, err)
+	assert.Greater(t, len(payloads), 1)
+
+	var decoded []Event
+	for _, p := range payloads {
+		assert.LessOrEqual(t, len(p), maxPayloadBytes)
+		evs, err := DecodePayload(p)
+		assert.NoError(t, err)
+		decoded = append(decoded, evs...)
+	}
+	assert.Equal(t, events, decoded)
+}
+
+func TestHub_CommentEventsOnlyForSubscribedStories(t *testing.T) {
+	hub := NewHub()
+	watching := hub.Subscribe([]int64{42})
+	other := hub.Subscribe(nil)
+	defer hub.Unsubscribe(watching)
+	defer hub.Unsubscribe(other)
+
+	hub.Publish([]Event{
+		{Type: EventStoryUpdate, StoryID: 7},
+		{Type: EventNewComment, StoryID: 42, CommentID: 1},
+	})
+
+	assert.Len(t, watching.C, 2)
+	assert.Len(t, other.C, 1)
+	assert.Equal(t, 2, hub.Subscribers())
+}
@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"context"
+)
+
+// TokenStore atomically refills and takes a token from a persisted bucket,
+// returning the tokens left and whether a token was taken.
+// storage.Store implements it.
+type TokenStore interface {
+	TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error)
+}
+
+// PostgresLimiter shares buckets between replicas through the database.
+type PostgresLimiter struct {
+	store TokenStore
+}
+
+// NewPostgresLimiter creates a limiter backed by store.
+func NewPostgresLimiter(store TokenStore) *PostgresLimiter {
+	return &PostgresLimiter{store: store}
+}
+
+func (p *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	tokens, allowed, err := p.store.TakeRateLimitToken(ctx, key, limit.Rate(), limit.Burst)
+	if err != nil {
+		return Result{}, err
+	}
+	return result(allowed, tokens, limit), nil
+}
@@ -0,0 +1,116 @@
+// Package ratelimit implements token-bucket rate limiting with pluggable
+// backends: in-memory for single instances and Postgres for multi-replica
+// deployments.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period on average, with bursts of up to Burst.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Rate is the bucket refill rate in tokens per second.
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formats the limit in the syntax accepted by ParseLimit.
+func (l Limit) String() string {
+	unit := "s"
+	switch l.Period {
+	case time.Minute:
+		unit = "m"
+	case time.Hour:
+		unit = "h"
+	}
+	s := fmt.Sprintf("%d/%s", l.Requests, unit)
+	if l.Burst != l.Requests {
+		s += ":" + strconv.Itoa(l.Burst)
+	}
+	return s
+}
+
+// ParseLimit parses "N/s", "N/m" or "N/h", optionally followed by ":burst".
+// The burst defaults to N.
+func ParseLimit(spec string) (Limit, error) {
+	spec = strings.TrimSpace(spec)
+	rate, burstStr, hasBurst := strings.Cut(spec, ":")
+
+	countStr, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected N/s, N/m or N/h", spec)
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: count must be a positive integer", spec)
+	}
+
+	var period time.Duration
+	switch unit {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit %q: unit must be s, m or h", spec)
+	}
+
+	l := Limit{Requests: count, Period: period, Burst: count}
+	if hasBurst {
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", spec)
+		}
+		l.Burst = burst
+	}
+	return l, nil
+}
+
+// Result describes the outcome of a single Allow call.
+type Result struct {
+	Allowed   bool
+	Limit     int           // Bucket capacity
+	Remaining int           // Whole tokens left after this request
+	Reset     time.Duration // Until the bucket is full again
+	// RetryAfter is how long to wait before a request would be allowed; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result derives the response metadata from the tokens left in a bucket.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	rate := limit.Rate()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("30/m")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 30, Period: time.Minute, Burst: 30}, l)
+	assert.InDelta(t, 0.5, l.Rate(), 1e-9)
+
+	l, err = ParseLimit("10/h:3")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 10, Period: time.Hour, Burst: 3}, l)
+	assert.Equal(t, "10/h:3", l.String())
+
+	for _, bad := range []string{"", "30", "0/m", "30/d", "30/m:0", "x/s"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemoryLimiter()
+	m.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2} // 1 token per second
+	ctx := context.Background()
+
+	r, _ := m.Allow(ctx, "k", limit)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+
+	r, _ = m.Allow(ctx, "k", limit)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Equal(t, 2*time.Second, r.Reset)
+
+	r, _ = m.Allow(ctx, "k", limit)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, time.Second, r.RetryAfter)
+
+	// Other keys have their own bucket
+	r, _ = m.Allow(ctx, "other", limit)
+	assert.True(t, r.Allowed)
+
+	// Refills at the configured rate, capped at the burst
+	now = now.Add(10 * time.Second)
+	r, _ = m.Allow(ctx, "k", limit)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+}
+
+type fakeTokenStore struct {
+	tokens  float64
+	allowed bool
+}
+
+func (f *fakeTokenStore) TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	return f.tokens, f.allowed, nil
+}
+
+func TestPostgresLimiter(t *testing.T) {
+	p := NewPostgresLimiter(&fakeTokenStore{tokens: 0.5, allowed: false})
+	r, err := p.Allow(context.Background(), "k", Limit{Requests: 1, Period: time.Second, Burst: 5})
+
+	assert.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 5, r.Limit)
+	assert.Equal(t, 500*time.Millisecond, r.RetryAfter)
+}
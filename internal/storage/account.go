@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AccountDeletionGracePeriod is how long a deleted account can still be
+// restored before PurgeDeletedAccounts removes it and all its data.
+const AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// RequestAccountDeletion marks an account for deletion and returns when the
+// request was made. Repeated requests keep the original timestamp.
+func (s *Store) RequestAccountDeletion(ctx context.Context, userID string) (time.Time, error) {
+	query := `
+		UPDATE auth_users SET deletion_requested_at = COALESCE(deletion_requested_at, NOW())
+		WHERE id = $1
+		RETURNING deletion_requested_at
+	`
+	var requestedAt time.Time
+	err := s.db.QueryRow(ctx, query, userID).Scan(&requestedAt)
+	return requestedAt, err
+}
+
+// CancelAccountDeletion restores an account that is pending deletion.
+func (s *Store) CancelAccountDeletion(ctx context.Context, userID string) error {
+	_, err := s.db.Exec(ctx, `UPDATE auth_users SET deletion_requested_at = NULL WHERE id = $1`, userID)
+	return err
+}
+
+// IsAccountActive reports whether a user exists and is not pending deletion.
+func (s *Store) IsAccountActive(ctx context.Context, userID string) (bool, error) {
+	var active bool
+	err := s.db.QueryRow(ctx, `SELECT deletion_requested_at IS NULL FROM auth_users WHERE id = $1`, userID).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return active, err
+}
+
+// PurgeDeletedAccounts permanently deletes accounts whose grace period has
+// expired. Interactions, chats, filters, watchlists and notifications are
+// removed by ON DELETE CASCADE.
+func (s *Store) PurgeDeletedAccounts(ctx context.Context, grace time.Duration) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM auth_users WHERE deletion_requested_at < $1`, time.Now().Add(-grace))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetOrCreateFeedToken returns the user's private feed token, storing
+// newToken if none exists yet.
+func (s *Store) GetOrCreateFeedToken(ctx context.Context, userID, newToken string) (string, error) {
+	query := `UPDATE auth_users SET feed_token = COALESCE(feed_token, $2) WHERE id = $1 RETURNING feed_token`
+	var token string
+	err := s.db.QueryRow(ctx, query, userID, newToken).Scan(&token)
+	return token, err
+}
+
+// SetFeedToken replaces the user's feed token, invalidating old feed URLs.
+func (s *Store) SetFeedToken(ctx context.Context, userID, token string) error {
+	_, err := s.db.Exec(ctx, `UPDATE auth_users SET feed_token = $2 WHERE id = $1`, userID, token)
+	return err
+}
+
+// GetUserIDByFeedToken resolves a feed token to a user ID. Accounts pending
+// deletion do not resolve. Returns ErrNotFound for unknown tokens.
+func (s *Store) GetUserIDByFeedToken(ctx context.Context, token string) (string, error) {
+	query := `SELECT id FROM auth_users WHERE feed_token = $1 AND deletion_requested_at IS NULL`
+	var userID string
+	err := s.db.QueryRow(ctx, query, token).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return userID, err
+}
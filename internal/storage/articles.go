@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ArticleStatusOK     = "ok"
+	ArticleStatusFailed = "failed"
+
+	ArticleSourceOrigin  = "origin"
+	ArticleSourceArchive = "archive"
+
+	ArticleOutcomeArticle = "article"
+)
+
+// ArticleContent is the cached extraction of a story's URL.
+type ArticleContent struct {
+	StoryID     int64     `json:"story_id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	ContentHTML string    `json:"content_html"`
+	ContentText string    `json:"content_text"`
+	CanIframe   bool      `json:"can_iframe"`
+	Status      string    `json:"status"`
+	HTTPStatus  *int      `json:"http_status,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	Preview     Preview   `json:"preview"`
+	Source      string    `json:"source"`                // ArticleSourceOrigin or ArticleSourceArchive
+	ArchiveURL  *string   `json:"archive_url,omitempty"` // Snapshot the content was extracted from
+	Outcome     string    `json:"outcome"`               // What the latest fetch found; see content.Outcome
+
+	ArchiveMissedAt *time.Time `json:"archive_missed_at,omitempty"` // When the archive last had no usable snapshot
+}
+
+// Preview is the Open Graph / Twitter card metadata and favicon of a page.
+type Preview struct {
+	Description *string    `json:"description,omitempty"`
+	ImageURL    *string    `json:"image_url,omitempty"`
+	SiteName    *string    `json:"site_name,omitempty"`
+	Author      *string    `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	FaviconURL  *string    `json:"favicon_url,omitempty"`
+}
+
+// IsZero reports whether no preview field is set.
+func (p Preview) IsZero() bool {
+	return p == Preview{}
+}
+
+// previewColumns and previewJoin add a story's preview to story queries.
+// Metadata fetched for a story's previous URL is ignored.
+const (
+	previewColumns = `ac.description, ac.image_url, ac.site_name, ac.author, ac.published_at, ac.favicon_url`
+	previewJoin    = ` LEFT JOIN article_contents ac ON ac.story_id = s.id AND ac.url = s.url`
+)
+
+const articleContentColumns = `story_id, url, title, content_html, content_text, can_iframe, status, http_status, error, fetched_at,
+	description, image_url, site_name, author, published_at, favicon_url, source, archive_url, outcome, archive_missed_at`
+
+func scanArticleContent(row pgx.Row) (*ArticleContent, error) {
+	var a ArticleContent
+	p := &a.Preview
+	err := row.Scan(&a.StoryID, &a.URL, &a.Title, &a.ContentHTML, &a.ContentText, &a.CanIframe, &a.Status, &a.HTTPStatus, &a.Error, &a.FetchedAt,
+		&p.Description, &p.ImageURL, &p.SiteName, &p.Author, &p.PublishedAt, &p.FaviconURL, &a.Source, &a.ArchiveURL, &a.Outcome, &a.ArchiveMissedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetArticleContent returns the cached content for a story, or ErrNotFound.
+func (s *Store) GetArticleContent(ctx context.Context, storyID int64) (*ArticleContent, error) {
+	query := `SELECT ` + articleContentColumns + ` FROM article_contents WHERE story_id = $1`
+	return scanArticleContent(s.db.QueryRow(ctx, query, storyID))
+}
+
+// previewReplaced is true when an upsert replaces the stored preview: on
+// success, on a URL change, or when a failed fetch such as a paywall teaser
+// still found preview metadata.
+const previewReplaced = `(EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url OR
+	num_nonnulls(EXCLUDED.description, EXCLUDED.image_url, EXCLUDED.site_name, EXCLUDED.author, EXCLUDED.published_at, EXCLUDED.favicon_url) > 0)`
+
+// SaveArticleContent records a fetch attempt and returns the stored row. A
+// failed attempt for the same URL keeps the previously extracted content,
+// and the previous preview unless it found a new one.
+func (s *Store) SaveArticleContent(ctx context.Context, a *ArticleContent) (*ArticleContent, error) {
+	query := `
+		INSERT INTO article_contents AS a (story_id, url, title, content_html, content_text, can_iframe, status, http_status, error, fetched_at,
+			description, image_url, site_name, author, published_at, favicon_url, source, archive_url, outcome)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (story_id) DO UPDATE SET
+			title = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.title ELSE a.title END,
+			content_html = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.content_html ELSE a.content_html END,
+			content_text = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.content_text ELSE a.content_text END,
+			can_iframe = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.can_iframe ELSE a.can_iframe END,
+			description = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.description ELSE a.description END,
+			image_url = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.image_url ELSE a.image_url END,
+			site_name = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.site_name ELSE a.site_name END,
+			author = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.author ELSE a.author END,
+			published_at = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.published_at ELSE a.published_at END,
+			favicon_url = CASE WHEN ` + previewReplaced + ` THEN EXCLUDED.favicon_url ELSE a.favicon_url END,
+			source = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.source ELSE a.source END,
+			archive_url = CASE WHEN EXCLUDED.status = 'ok' OR a.url != EXCLUDED.url THEN EXCLUDED.archive_url ELSE a.archive_url END,
+			url = EXCLUDED.url,
+			status = EXCLUDED.status,
+			http_status = EXCLUDED.http_status,
+			error = EXCLUDED.error,
+			outcome = EXCLUDED.outcome,
+			archive_missed_at = CASE WHEN a.url != EXCLUDED.url THEN NULL ELSE a.archive_missed_at END,
+			fetched_at = EXCLUDED.fetched_at
+		RETURNING ` + articleContentColumns
+	p := a.Preview
+	return scanArticleContent(s.db.QueryRow(ctx, query, a.StoryID, a.URL, a.Title, a.ContentHTML, a.ContentText, a.CanIframe, a.Status, a.HTTPStatus, a.Error,
+		p.Description, p.ImageURL, p.SiteName, p.Author, p.PublishedAt, p.FaviconURL, a.source(), a.ArchiveURL, a.outcome()))
+}
+
+// RecordArchiveMiss notes that the archive had no usable snapshot of the
+// story's current URL. It does nothing if that URL was never fetched.
+func (s *Store) RecordArchiveMiss(ctx context.Context, storyID int64, url string) error {
+	_, err := s.db.Exec(ctx, `UPDATE article_contents SET archive_missed_at = NOW() WHERE story_id = $1 AND url = $2`, storyID, url)
+	return err
+}
+
+func (a *ArticleContent) source() string {
+	if a.Source == "" {
+		return ArticleSourceOrigin
+	}
+	return a.Source
+}
+
+func (a *ArticleContent) outcome() string {
+	if a.Outcome == "" {
+		return ArticleOutcomeArticle
+	}
+	return a.Outcome
+}
+
+// GetStoriesWithoutPreview returns ranked and recent link stories whose
+// current URL has not been fetched yet, highest ranked first.
+func (s *Store) GetStoriesWithoutPreview(ctx context.Context, limit int) ([]Story, error) {
+	query := `
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain
+		FROM stories s
+		WHERE s.url != ''
+			AND (s.hn_rank IS NOT NULL OR s.posted_at > NOW() - INTERVAL '2 days')
+			AND NOT EXISTS (SELECT 1 FROM article_contents ac WHERE ac.story_id = s.id AND ac.url = s.url)
+		ORDER BY s.hn_rank ASC NULLS LAST, s.posted_at DESC
+		LIMIT $1
+	`
+	return s.queryStories(ctx, query, limit)
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type DomainStats struct {
+	Domain        string     `json:"domain"`
+	StoryCount    int        `json:"story_count"`
+	AvgScore      float64    `json:"avg_score"`
+	MaxScore      int        `json:"max_score"`
+	TotalComments int        `json:"total_comments"`
+	LastPostedAt  *time.Time `json:"last_posted_at,omitempty"`
+}
+
+// GetStoriesByDomain returns stories linking to a site, like HN's from?site=.
+// sortStrategy is "votes" or anything else for newest first. Stories muted by
+// userID (empty for anonymous readers) are excluded.
+func (s *Store) GetStoriesByDomain(ctx context.Context, domain string, limit, offset int, sortStrategy, userID string) ([]Story, error) {
+	orderBy := "s.posted_at DESC"
+	if sortStrategy == "votes" {
+		orderBy = "s.score DESC"
+	}
+
+	args := []interface{}{domain, limit, offset}
+	query := `
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain
+		FROM stories s
+		WHERE s.domain = $1` + muteFilter(userID, &args) + `
+		ORDER BY ` + orderBy + `
+		LIMIT $2 OFFSET $3
+	`
+	return s.queryStories(ctx, query, args...)
+}
+
+// GetDomainStats aggregates a single site's stories posted since the given time.
+func (s *Store) GetDomainStats(ctx context.Context, domain string, since time.Time) (*DomainStats, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(score), 0), COALESCE(MAX(score), 0), COALESCE(SUM(descendants), 0), MAX(posted_at)
+		FROM stories
+		WHERE domain = $1 AND posted_at >= $2
+	`
+	stats := DomainStats{Domain: domain}
+	err := s.db.QueryRow(ctx, query, domain, since).Scan(&stats.StoryCount, &stats.AvgScore, &stats.MaxScore, &stats.TotalComments, &stats.LastPostedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetTopDomains ranks sites by number of stories posted since the given time.
+func (s *Store) GetTopDomains(ctx context.Context, since time.Time, limit int) ([]DomainStats, error) {
+	query := `
+		SELECT domain, COUNT(*) AS story_count, AVG(score), MAX(score), SUM(descendants), MAX(posted_at)
+		FROM stories
+		WHERE domain IS NOT NULL AND posted_at >= $1
+		GROUP BY domain
+		ORDER BY story_count DESC, SUM(score) DESC
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []DomainStats
+	for rows.Next() {
+		var d DomainStats
+		if err := rows.Scan(&d.Domain, &d.StoryCount, &d.AvgScore, &d.MaxScore, &d.TotalComments, &d.LastPostedAt); err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
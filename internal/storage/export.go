@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rajeshkumarblr/hn_station/internal/urlnorm"
+)
+
+// InteractionExport is one story a user has read, saved, hidden or annotated.
+type InteractionExport struct {
+	StoryID   int64     `json:"story_id"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	By        string    `json:"by"`
+	PostedAt  time.Time `json:"posted_at"`
+	IsRead    bool      `json:"is_read"`
+	IsSaved   bool      `json:"is_saved"`
+	IsHidden  bool      `json:"is_hidden"`
+	Tags      []string  `json:"tags"`
+	Note      *string   `json:"note,omitempty"`
+	Folder    *string   `json:"folder,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	LastVisitedAt     *time.Time `json:"last_visited_at,omitempty"`
+	LastSeenCommentID *int64     `json:"last_seen_comment_id,omitempty"` // Newest comment seen on the last visit
+}
+
+// GetInteractionsForExport returns all of a user's story interactions, most recently updated first.
+func (s *Store) GetInteractionsForExport(ctx context.Context, userID string) ([]InteractionExport, error) {
+	query := `
+		SELECT s.id, s.title, COALESCE(s.url, ''), COALESCE(s.by, ''), s.posted_at,
+		       ui.is_read, ui.is_saved, ui.is_hidden, ui.tags, ui.note, ui.folder, ui.updated_at, ui.last_visited_at,
+		       ui.last_seen_comment_id
+		FROM user_interactions ui
+		INNER JOIN stories s ON s.id = ui.story_id
+		WHERE ui.user_id = $1
+		  AND (ui.is_read OR ui.is_saved OR ui.is_hidden OR ui.note IS NOT NULL OR cardinality(ui.tags) > 0
+		       OR ui.last_visited_at IS NOT NULL)
+		ORDER BY ui.updated_at DESC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []InteractionExport
+	for rows.Next() {
+		var it InteractionExport
+		if err := rows.Scan(&it.StoryID, &it.Title, &it.URL, &it.By, &it.PostedAt,
+			&it.IsRead, &it.IsSaved, &it.IsHidden, &it.Tags, &it.Note, &it.Folder, &it.UpdatedAt, &it.LastVisitedAt, &it.LastSeenCommentID); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// GetAllChatMessages returns a user's chat history across all stories.
+func (s *Store) GetAllChatMessages(ctx context.Context, userID string) ([]ChatMessage, error) {
+	query := `SELECT id, user_id, story_id, role, content, created_at FROM chat_messages WHERE user_id = $1::uuid ORDER BY story_id ASC, created_at ASC`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []ChatMessage
+	for rows.Next() {
+		var m ChatMessage
+		if err := rows.Scan(&m.ID, &m.UserID, &m.StoryID, &m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// ExistingStoryIDs returns which of the given story IDs are stored.
+func (s *Store) ExistingStoryIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	rows, err := s.db.Query(ctx, `SELECT id FROM stories WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[int]bool, len(ids))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+// StoryIDsByURL maps article URLs to the newest submission of each, matching
+// on the normalized URL like GetStoriesByURL. URLs without a submission are
+// left out.
+func (s *Store) StoryIDsByURL(ctx context.Context, rawURLs []string) (map[string]int, error) {
+	byKey := make(map[string][]string, len(rawURLs))
+	keys := make([]string, 0, len(rawURLs))
+	for _, rawURL := range rawURLs {
+		if key := urlnorm.Normalize(rawURL); key != "" {
+			if _, ok := byKey[key]; !ok {
+				keys = append(keys, key)
+			}
+			byKey[key] = append(byKey[key], rawURL)
+		}
+	}
+
+	query := `
+		SELECT DISTINCT ON (normalized_url) normalized_url, id
+		FROM stories
+		WHERE normalized_url = ANY($1)
+		ORDER BY normalized_url, posted_at DESC
+	`
+	rows, err := s.db.Query(ctx, query, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int, len(rawURLs))
+	for rows.Next() {
+		var key string
+		var id int
+		if err := rows.Scan(&key, &id); err != nil {
+			return nil, err
+		}
+		for _, rawURL := range byKey[key] {
+			ids[rawURL] = id
+		}
+	}
+	return ids, rows.Err()
+}
+
+// ImportedInteraction is a bookmark resolved to a story. Empty fields leave
+// the user's existing annotations unchanged.
+type ImportedInteraction struct {
+	StoryID int
+	State   ImportState
+	Tags    []string // Saved stories only
+	Note    string
+	Folder  string // Saved stories only
+}
+
+// ImportState is what importing a bookmark marks its story as.
+type ImportState int
+
+const (
+	ImportSaved ImportState = iota
+	ImportRead
+	ImportHidden
+)
+
+// ImportInteractions marks stories as saved, read or hidden in a single
+// round trip. Saved stories also get the bookmark's tags, note and folder;
+// read and hidden stories only the note, so importing history never saves
+// anything. Annotations that fail validation, and tags that would take a
+// story past maxTagsPerStory, are left out rather than cut short. Returns the
+// IDs of stories imported without some of their annotations.
+func (s *Store) ImportInteractions(ctx context.Context, userID string, items []ImportedInteraction) ([]int, error) {
+	readQuery := `
+		INSERT INTO user_interactions (user_id, story_id, is_read, note, updated_at)
+		VALUES ($1, $2, TRUE, NULLIF($3, ''), NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			is_read = TRUE,
+			note = COALESCE(NULLIF($3, ''), user_interactions.note),
+			updated_at = NOW()
+		RETURNING TRUE
+	`
+	hiddenQuery := `
+		INSERT INTO user_interactions (user_id, story_id, is_hidden, note, updated_at)
+		VALUES ($1, $2, TRUE, NULLIF($3, ''), NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			is_hidden = TRUE,
+			note = COALESCE(NULLIF($3, ''), user_interactions.note),
+			updated_at = NOW()
+		RETURNING TRUE
+	`
+	savedQuery := `
+		INSERT INTO user_interactions (user_id, story_id, is_saved, tags, note, folder, updated_at)
+		VALUES ($1, $2, TRUE, $3, NULLIF($4, ''), NULLIF($5, ''), NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			is_saved = TRUE,
+			tags = CASE WHEN cardinality(` + mergedTags + `) <= ` + strconv.Itoa(maxTagsPerStory) + `
+				THEN ` + mergedTags + ` ELSE user_interactions.tags END,
+			note = COALESCE(NULLIF($4, ''), user_interactions.note),
+			folder = COALESCE(NULLIF($5, ''), user_interactions.folder),
+			updated_at = NOW()
+		RETURNING tags @> $3
+	`
+
+	var partial []int
+	batch := &pgx.Batch{}
+	for _, it := range items {
+		complete := true
+		note := it.Note
+		if len(note) > maxNoteLen {
+			note, complete = "", false
+		}
+		switch it.State {
+		case ImportRead:
+			batch.Queue(readQuery, userID, it.StoryID, note)
+		case ImportHidden:
+			batch.Queue(hiddenQuery, userID, it.StoryID, note)
+		default:
+			tags := []string{}
+			if len(it.Tags) > 0 {
+				normalized, err := normalizeTags(it.Tags)
+				if err == nil && len(normalized) <= maxTagsPerStory {
+					tags = normalized
+				} else {
+					complete = false
+				}
+			}
+			folder := strings.TrimSpace(it.Folder)
+			if len(folder) > maxFolderNameLen {
+				folder, complete = "", false
+			}
+			batch.Queue(savedQuery, userID, it.StoryID, tags, note, folder)
+		}
+		if !complete {
+			partial = append(partial, it.StoryID)
+		}
+	}
+
+	br := s.db.SendBatch(ctx, batch)
+	defer br.Close()
+	for _, it := range items {
+		var tagsAdded bool
+		if err := br.QueryRow().Scan(&tagsAdded); err != nil {
+			return nil, fmt.Errorf("import story %d: %w", it.StoryID, err)
+		}
+		if !tagsAdded && !slices.Contains(partial, it.StoryID) {
+			partial = append(partial, it.StoryID)
+		}
+	}
+	return partial, br.Close()
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rajeshkumarblr/hn_station/internal/urlnorm"
+)
+
+const (
+	FilterKindDomain  = "domain"
+	FilterKindKeyword = "keyword"
+	FilterKindRegex   = "regex"
+	FilterKindAuthor  = "author"
+
+	maxFilterPatternLen = 200
+	maxFiltersPerUser   = 200
+)
+
+// ErrInvalidFilter is returned when a mute rule is malformed.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// ErrNotFound is returned when a row owned by the user does not exist.
+var ErrNotFound = errors.New("not found")
+
+type UserFilter struct {
+	ID        int       `json:"id"`
+	Kind      string    `json:"kind"`
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// muteFilterClause excludes stories matched by any of the user's mute rules.
+// userParam is the placeholder holding the user ID, e.g. "$1".
+func muteFilterClause(userParam string) string {
+	return ` AND NOT EXISTS (
+		SELECT 1 FROM user_filters f
+		WHERE f.user_id = ` + userParam + ` AND (
+			(f.kind = 'domain' AND (s.domain = f.pattern OR right(s.domain, length(f.pattern) + 1) = '.' || f.pattern))
+			OR (f.kind IN ('keyword', 'regex') AND s.title ~* f.match_regex)
+			OR (f.kind = 'author' AND s.by = f.pattern)
+		)
+	)`
+}
+
+// muteFilter returns muteFilterClause for a signed-in user, adding the user
+// ID to args, or "" for anonymous queries. Every story listing with a user
+// goes through it so muted stories never reach the client.
+func muteFilter(userID string, args *[]interface{}) string {
+	if userID == "" {
+		return ""
+	}
+	*args = append(*args, userID)
+	return muteFilterClause(fmt.Sprintf("$%d", len(*args)))
+}
+
+// normalizeFilter validates a rule and returns its stored pattern and match regex.
+func normalizeFilter(kind, pattern string) (string, *string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || len(pattern) > maxFilterPatternLen {
+		return "", nil, fmt.Errorf("%w: pattern must be 1-%d characters", ErrInvalidFilter, maxFilterPatternLen)
+	}
+
+	switch kind {
+	case FilterKindDomain:
+		domain := urlnorm.Domain(pattern)
+		if domain == "" || !strings.Contains(domain, ".") {
+			return "", nil, fmt.Errorf("%w: %q is not a domain", ErrInvalidFilter, pattern)
+		}
+		return domain, nil, nil
+	case FilterKindKeyword:
+		// Whole-word, case-insensitive match. The escaping from QuoteMeta is
+		// also valid in Postgres regexes.
+		re := `(^|\W)` + regexp.QuoteMeta(pattern) + `(\W|$)`
+		return pattern, &re, nil
+	case FilterKindRegex:
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		return pattern, &pattern, nil
+	case FilterKindAuthor:
+		if strings.ContainsAny(pattern, " \t/") {
+			return "", nil, fmt.Errorf("%w: %q is not an HN username", ErrInvalidFilter, pattern)
+		}
+		return pattern, nil, nil
+	default:
+		return "", nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidFilter, kind)
+	}
+}
+
+// validateRegexInDB makes sure Postgres accepts the regex too, since a bad
+// pattern would otherwise break every story query for the user.
+func (s *Store) validateRegexInDB(ctx context.Context, re *string) error {
+	if re == nil {
+		return nil
+	}
+	var ok bool
+	if err := s.db.QueryRow(ctx, `SELECT '' ~* $1`, *re).Scan(&ok); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			return fmt.Errorf("%w: %s", ErrInvalidFilter, pgErr.Message)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUserFilters returns a user's mute rules, oldest first.
+func (s *Store) GetUserFilters(ctx context.Context, userID string) ([]UserFilter, error) {
+	query := `SELECT id, kind, pattern, created_at FROM user_filters WHERE user_id = $1 ORDER BY created_at ASC, id ASC`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []UserFilter
+	for rows.Next() {
+		var f UserFilter
+		if err := rows.Scan(&f.ID, &f.Kind, &f.Pattern, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// CreateUserFilter adds a mute rule. Adding an existing rule is a no-op that
+// returns the existing row.
+func (s *Store) CreateUserFilter(ctx context.Context, userID, kind, pattern string) (*UserFilter, error) {
+	pattern, matchRegex, err := normalizeFilter(kind, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateRegexInDB(ctx, matchRegex); err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM user_filters WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count >= maxFiltersPerUser {
+		return nil, fmt.Errorf("%w: at most %d filters per user", ErrInvalidFilter, maxFiltersPerUser)
+	}
+
+	query := `
+		INSERT INTO user_filters (user_id, kind, pattern, match_regex)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, kind, pattern) DO UPDATE SET match_regex = EXCLUDED.match_regex
+		RETURNING id, kind, pattern, created_at
+	`
+	var f UserFilter
+	if err := s.db.QueryRow(ctx, query, userID, kind, pattern, matchRegex).Scan(&f.ID, &f.Kind, &f.Pattern, &f.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// UpdateUserFilter replaces the kind and pattern of an existing rule.
+func (s *Store) UpdateUserFilter(ctx context.Context, userID string, id int, kind, pattern string) (*UserFilter, error) {
+	pattern, matchRegex, err := normalizeFilter(kind, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateRegexInDB(ctx, matchRegex); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE user_filters SET kind = $3, pattern = $4, match_regex = $5
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, kind, pattern, created_at
+	`
+	var f UserFilter
+	err = s.db.QueryRow(ctx, query, id, userID, kind, pattern, matchRegex).Scan(&f.ID, &f.Kind, &f.Pattern, &f.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, fmt.Errorf("%w: rule already exists", ErrInvalidFilter)
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+// DeleteUserFilter removes a rule owned by the user.
+func (s *Store) DeleteUserFilter(ctx context.Context, userID string, id int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM user_filters WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	LinkStatusOK    = "ok"
+	LinkStatusError = "error" // Failing, possibly transiently
+	LinkStatusDead  = "dead"
+)
+
+// LinkCheck is the latest health check of a story's URL.
+type LinkCheck struct {
+	StoryID    int64      `json:"story_id"`
+	URL        string     `json:"url"`
+	Status     string     `json:"status"`
+	HTTPStatus *int       `json:"http_status,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	Failures   int        `json:"failures"`
+	CheckedAt  time.Time  `json:"checked_at"`
+	LastOKAt   *time.Time `json:"last_ok_at,omitempty"`
+}
+
+const linkCheckColumns = `story_id, url, status, http_status, error, failures, checked_at, last_ok_at`
+
+func scanLinkCheck(row pgx.Row) (*LinkCheck, error) {
+	var c LinkCheck
+	err := row.Scan(&c.StoryID, &c.URL, &c.Status, &c.HTTPStatus, &c.Error, &c.Failures, &c.CheckedAt, &c.LastOKAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetLinkCheck returns the latest check of a story's URL, or ErrNotFound.
+func (s *Store) GetLinkCheck(ctx context.Context, storyID int64) (*LinkCheck, error) {
+	query := `SELECT ` + linkCheckColumns + ` FROM link_checks WHERE story_id = $1`
+	return scanLinkCheck(s.db.QueryRow(ctx, query, storyID))
+}
+
+// SaveLinkCheck records a check, replacing the previous one.
+func (s *Store) SaveLinkCheck(ctx context.Context, c *LinkCheck) error {
+	query := `
+		INSERT INTO link_checks AS l (story_id, url, status, http_status, error, failures, checked_at, last_ok_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), CASE WHEN $3 = 'ok' THEN NOW() END)
+		ON CONFLICT (story_id) DO UPDATE SET
+			url = EXCLUDED.url,
+			status = EXCLUDED.status,
+			http_status = EXCLUDED.http_status,
+			error = EXCLUDED.error,
+			failures = EXCLUDED.failures,
+			checked_at = EXCLUDED.checked_at,
+			last_ok_at = CASE WHEN EXCLUDED.status = 'ok' THEN EXCLUDED.checked_at
+				WHEN l.url = EXCLUDED.url THEN l.last_ok_at END
+	`
+	_, err := s.db.Exec(ctx, query, c.StoryID, c.URL, c.Status, c.HTTPStatus, c.Error, c.Failures)
+	return err
+}
+
+// GetStoriesDueForLinkCheck returns saved stories whose URL has never been
+// checked or whose last check has expired: a week for healthy links, a day
+// for failing ones and a month for dead ones.
+func (s *Store) GetStoriesDueForLinkCheck(ctx context.Context, limit int) ([]Story, error) {
+	query := `
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain
+		FROM stories s
+		LEFT JOIN link_checks l ON l.story_id = s.id AND l.url = s.url
+		WHERE s.url != ''
+			AND EXISTS (SELECT 1 FROM user_interactions ui WHERE ui.story_id = s.id AND ui.is_saved = TRUE)
+			AND (l.story_id IS NULL
+				OR (l.status = 'ok' AND l.checked_at < NOW() - INTERVAL '7 days')
+				OR (l.status = 'error' AND l.checked_at < NOW() - INTERVAL '1 day')
+				OR (l.status = 'dead' AND l.checked_at < NOW() - INTERVAL '30 days'))
+		ORDER BY l.checked_at ASC NULLS FIRST, s.posted_at ASC
+		LIMIT $1
+	`
+	return s.queryStories(ctx, query, limit)
+}
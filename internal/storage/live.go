@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LiveChannel is the Postgres NOTIFY channel the ingest service publishes
+// live front-page events on.
+const LiveChannel = "hn_live"
+
+// StorySnapshot is the state of a story that live events are derived from.
+type StorySnapshot struct {
+	ID          int64
+	Title       string
+	URL         string
+	By          string
+	Score       int
+	Descendants int
+	Rank        *int
+	CreatedAt   time.Time
+}
+
+// LiveComment is a newly ingested comment, with its text truncated.
+type LiveComment struct {
+	ID       int64
+	StoryID  int64
+	ParentID *int64
+	By       string
+	Text     string
+	PostedAt time.Time
+}
+
+// GetLiveSnapshot returns ranked stories and stories posted within recent,
+// keyed by ID, along with the database's current time.
+func (s *Store) GetLiveSnapshot(ctx context.Context, recent time.Duration) (map[int64]StorySnapshot, time.Time, error) {
+	var now time.Time
+	if err := s.db.QueryRow(ctx, `SELECT NOW()`).Scan(&now); err != nil {
+		return nil, now, err
+	}
+
+	query := `
+		SELECT id, title, COALESCE(url, ''), COALESCE(by, ''), score, descendants, hn_rank, created_at
+		FROM stories
+		WHERE hn_rank IS NOT NULL OR posted_at >= $1
+	`
+	rows, err := s.db.Query(ctx, query, now.Add(-recent))
+	if err != nil {
+		return nil, now, err
+	}
+	defer rows.Close()
+
+	snapshot := make(map[int64]StorySnapshot)
+	for rows.Next() {
+		var st StorySnapshot
+		if err := rows.Scan(&st.ID, &st.Title, &st.URL, &st.By, &st.Score, &st.Descendants, &st.Rank, &st.CreatedAt); err != nil {
+			return nil, now, err
+		}
+		snapshot[st.ID] = st
+	}
+	return snapshot, now, rows.Err()
+}
+
+// GetCommentsSince returns up to limit comments first stored at or after since, oldest first.
+func (s *Store) GetCommentsSince(ctx context.Context, since time.Time, limit int) ([]LiveComment, error) {
+	query := `
+		SELECT id, story_id, parent_id, COALESCE(by, ''), left(COALESCE(text, ''), 500), posted_at
+		FROM comments
+		WHERE created_at >= $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []LiveComment
+	for rows.Next() {
+		var c LiveComment
+		if err := rows.Scan(&c.ID, &c.StoryID, &c.ParentID, &c.By, &c.Text, &c.PostedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// Notify sends a payload on a Postgres NOTIFY channel.
+func (s *Store) Notify(ctx context.Context, channel, payload string) error {
+	_, err := s.db.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
+	return err
+}
+
+// Listen holds a dedicated connection subscribed to channel and calls
+// onPayload for each notification. It blocks until ctx is cancelled or the
+// connection fails.
+func (s *Store) Listen(ctx context.Context, channel string, onPayload func(payload string)) error {
+	pooled, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection will hold LISTEN state, so take it out of the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onPayload(n.Payload)
+	}
+}
+
+// BumpIngestVersion records a completed ingestion run and returns the new version.
+func (s *Store) BumpIngestVersion(ctx context.Context) (int64, error) {
+	query := `
+		INSERT INTO ingest_state (id, version, updated_at) VALUES (TRUE, 1, NOW())
+		ON CONFLICT (id) DO UPDATE SET version = ingest_state.version + 1, updated_at = NOW()
+		RETURNING version
+	`
+	var version int64
+	err := s.db.QueryRow(ctx, query).Scan(&version)
+	return version, err
+}
+
+// GetIngestVersion returns the number of completed ingestion runs.
+func (s *Store) GetIngestVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM ingest_state`).Scan(&version)
+	return version, err
+}
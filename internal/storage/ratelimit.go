@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// TakeRateLimitToken refills the bucket for key at rate tokens per second up
+// to burst, then takes one token if available. The row lock taken by the
+// upsert serialises concurrent requests for the same key across replicas.
+func (s *Store) TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $3::float8 - 1, TRUE, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE
+				WHEN LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at) * $2) >= 1
+				THEN LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at) * $2) - 1
+				ELSE LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at) * $2)
+			END,
+			allowed = LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM clock_timestamp() - b.updated_at) * $2) >= 1,
+			updated_at = clock_timestamp()
+		RETURNING tokens, allowed
+	`
+	var tokens float64
+	var allowed bool
+	err := s.db.QueryRow(ctx, query, key, rate, burst).Scan(&tokens, &allowed)
+	return tokens, allowed, err
+}
+
+// DeleteStaleRateLimitBuckets removes buckets idle for longer than olderThan.
+func (s *Store) DeleteStaleRateLimitBuckets(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxTagLen        = 50
+	maxTagsPerStory  = 20
+	maxNoteLen       = 10000
+	maxFolderNameLen = 100
+)
+
+// ErrInvalidInput is returned when tags, notes or folders fail validation.
+// The wrapped message is safe to show users.
+var ErrInvalidInput = errors.New("invalid input")
+
+// mergedTags is a story's existing tags combined with the ones being added
+// (EXCLUDED.tags), de-duplicated and sorted.
+const mergedTags = `ARRAY(SELECT DISTINCT t FROM unnest(user_interactions.tags || EXCLUDED.tags) AS t ORDER BY t)`
+
+// savedSearchVector indexes a user's tags and note alongside the story title.
+// Requires user_interactions aliased as ui.
+const savedSearchVector = `to_tsvector('english', array_to_string(ui.tags, ' ') || ' ' || COALESCE(ui.note, ''))`
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type FolderCount struct {
+	Folder string `json:"folder"`
+	Count  int    `json:"count"`
+}
+
+// normalizeTag lowercases a tag and collapses inner whitespace to dashes.
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// normalizeTags cleans, de-duplicates and validates a list of tags.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, t := range tags {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if len(t) > maxTagLen {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidInput, t, maxTagLen)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", ErrInvalidInput)
+	}
+	return out, nil
+}
+
+// AddStoryTags tags a story, saving it if it was not saved yet. A story keeps
+// at most maxTagsPerStory tags; adding more fails with ErrInvalidInput and
+// leaves the story unchanged. Returns the story's resulting tag list.
+func (s *Store) AddStoryTags(ctx context.Context, userID string, storyID int, tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	tooMany := fmt.Errorf("%w: a story can have at most %d tags", ErrInvalidInput, maxTagsPerStory)
+	if len(tags) > maxTagsPerStory {
+		return nil, tooMany
+	}
+
+	// The WHERE skips the update, returning no row, when the merged set is too large
+	query := `
+		INSERT INTO user_interactions (user_id, story_id, is_saved, tags, updated_at)
+		VALUES ($1, $2, TRUE, $3, NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			is_saved = TRUE,
+			tags = ` + mergedTags + `,
+			updated_at = NOW()
+		WHERE cardinality(` + mergedTags + `) <= ` + strconv.Itoa(maxTagsPerStory) + `
+		RETURNING tags
+	`
+	var result []string
+	if err := s.db.QueryRow(ctx, query, userID, storyID, tags).Scan(&result); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tooMany
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveStoryTag removes a tag from a story. Removing a missing tag is a no-op.
+func (s *Store) RemoveStoryTag(ctx context.Context, userID string, storyID int, tag string) error {
+	query := `UPDATE user_interactions SET tags = array_remove(tags, $3) WHERE user_id = $1 AND story_id = $2`
+	_, err := s.db.Exec(ctx, query, userID, storyID, normalizeTag(tag))
+	return err
+}
+
+// UpdateSavedAnnotations sets the note and/or folder of a story. A nil
+// argument leaves that field unchanged; an empty string clears it.
+func (s *Store) UpdateSavedAnnotations(ctx context.Context, userID string, storyID int, note, folder *string) error {
+	if note != nil && len(*note) > maxNoteLen {
+		return fmt.Errorf("%w: note is longer than %d characters", ErrInvalidInput, maxNoteLen)
+	}
+	if folder != nil {
+		trimmed := strings.TrimSpace(*folder)
+		if len(trimmed) > maxFolderNameLen {
+			return fmt.Errorf("%w: folder name is longer than %d characters", ErrInvalidInput, maxFolderNameLen)
+		}
+		folder = &trimmed
+	}
+
+	query := `
+		INSERT INTO user_interactions (user_id, story_id, note, folder, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			note = CASE WHEN $3::text IS NULL THEN user_interactions.note ELSE NULLIF($3, '') END,
+			folder = CASE WHEN $4::text IS NULL THEN user_interactions.folder ELSE NULLIF($4, '') END
+	`
+	_, err := s.db.Exec(ctx, query, userID, storyID, note, folder)
+	return err
+}
+
+// GetUserTags lists the tags used on a user's saved stories with usage counts.
+func (s *Store) GetUserTags(ctx context.Context, userID string) ([]TagCount, error) {
+	query := `
+		SELECT t, COUNT(*)
+		FROM user_interactions ui, unnest(ui.tags) AS t
+		WHERE ui.user_id = $1 AND ui.is_saved = TRUE
+		GROUP BY t
+		ORDER BY COUNT(*) DESC, t ASC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []TagCount
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.Tag, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// GetUserFolders lists a user's folders with the number of saved stories in each.
+func (s *Store) GetUserFolders(ctx context.Context, userID string) ([]FolderCount, error) {
+	query := `
+		SELECT folder, COUNT(*)
+		FROM user_interactions
+		WHERE user_id = $1 AND is_saved = TRUE AND folder IS NOT NULL
+		GROUP BY folder
+		ORDER BY folder ASC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []FolderCount
+	for rows.Next() {
+		var f FolderCount
+		if err := rows.Scan(&f.Folder, &f.Count); err != nil {
+			return nil, err
+		}
+		folders = append(folders, f)
+	}
+	return folders, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Rust ", "to  read", "rust", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rust", "to-read"}, tags)
+
+	_, err = normalizeTags([]string{"  "})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func manyTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%02d", i)
+	}
+	return tags
+}
+
+func TestAddStoryTags_RejectsTooManyTags(t *testing.T) {
+	_, err := (&Store{}).AddStoryTags(context.Background(), "user", 1, manyTags(maxTagsPerStory+1))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestAddStoryTags_RejectsMergedOverflow_Integration(t *testing.T) {
+	store := integrationStore(t)
+	ctx := context.Background()
+
+	user, err := store.UpsertAuthUser(ctx, fmt.Sprintf("tags-test-%d", time.Now().UnixNano()), "tags@example.com", "Tags Test", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { store.db.Exec(ctx, `DELETE FROM auth_users WHERE id = $1`, user.ID) })
+	require.NoError(t, store.UpsertStory(ctx, Story{ID: 990101, Title: "Tags test", PostedAt: time.Now()}))
+	t.Cleanup(func() { store.db.Exec(ctx, `DELETE FROM stories WHERE id = 990101`) })
+
+	tags, err := store.AddStoryTags(ctx, user.ID, 990101, manyTags(maxTagsPerStory))
+	require.NoError(t, err)
+	assert.Len(t, tags, maxTagsPerStory)
+
+	_, err = store.AddStoryTags(ctx, user.ID, 990101, []string{"one-too-many"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	// Re-adding existing tags does not grow the set
+	tags, err = store.AddStoryTags(ctx, user.ID, 990101, []string{"tag-00"})
+	require.NoError(t, err)
+	assert.Len(t, tags, maxTagsPerStory)
+	assert.NotContains(t, tags, "one-too-many")
+}
@@ -2,13 +2,16 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgvector "github.com/pgvector/pgvector-go"
+	"github.com/rajeshkumarblr/hn_station/internal/urlnorm"
 )
 
 type Story struct {
@@ -25,8 +28,14 @@ type Story struct {
 	IsSaved     *bool            `json:"is_saved,omitempty"`
 	IsHidden    *bool            `json:"is_hidden,omitempty"`
 	Summary     *string          `json:"summary,omitempty"`
+	Domain      *string          `json:"domain,omitempty"`
 	Embedding   *pgvector.Vector `json:"-"`
 	Similarity  *float64         `json:"similarity,omitempty"`
+	NewComments *int             `json:"new_comments,omitempty"` // Since the user's last visit; nil if never visited
+	Tags        []string         `json:"tags,omitempty"`
+	Note        *string          `json:"note,omitempty"`
+	Folder      *string          `json:"folder,omitempty"`
+	Preview     *Preview         `json:"preview,omitempty"` // From the last article fetch; nil until fetched
 }
 
 type AuthUser struct {
@@ -40,6 +49,8 @@ type AuthUser struct {
 	LastSeen     *time.Time `json:"last_seen"` // Pointer to handle nulls
 	GeminiAPIKey string     `json:"-"`         // Never expose to frontend
 	CreatedAt    time.Time  `json:"created_at"`
+
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"` // Set while the account is pending deletion
 }
 
 type AppStats struct {
@@ -49,6 +60,12 @@ type AppStats struct {
 	TotalComments     int `json:"total_comments"`
 }
 
+// newCommentsColumn counts comments above the user's comment watermark (NULL
+// if never visited), the same comments the story view flags as is_new.
+// Requires stories aliased as s and user_interactions as ui.
+const newCommentsColumn = `CASE WHEN ui.last_visited_at IS NULL THEN NULL
+	ELSE (SELECT COUNT(*) FROM comments c WHERE c.story_id = s.id AND c.id > COALESCE(ui.last_seen_comment_id, 0))::int END`
+
 type Store struct {
 	db *pgxpool.Pool
 }
@@ -59,8 +76,8 @@ func New(db *pgxpool.Pool) *Store {
 
 func (s *Store) UpsertStory(ctx context.Context, story Story) error {
 	query := `
-		INSERT INTO stories (id, title, url, score, by, descendants, posted_at, hn_rank, embedding, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		INSERT INTO stories (id, title, url, score, by, descendants, posted_at, hn_rank, embedding, normalized_url, domain, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NOW())
 		ON CONFLICT (id) DO UPDATE
 		SET title = EXCLUDED.title,
 			url = EXCLUDED.url,
@@ -69,20 +86,63 @@ func (s *Store) UpsertStory(ctx context.Context, story Story) error {
 			descendants = EXCLUDED.descendants,
 			posted_at = EXCLUDED.posted_at,
 			hn_rank = EXCLUDED.hn_rank,
-			embedding = COALESCE(EXCLUDED.embedding, stories.embedding);
+			embedding = COALESCE(EXCLUDED.embedding, stories.embedding),
+			normalized_url = EXCLUDED.normalized_url,
+			domain = EXCLUDED.domain;
 	`
-	_, err := s.db.Exec(ctx, query, story.ID, story.Title, story.URL, story.Score, story.By, story.Descendants, story.PostedAt, story.HNRank, story.Embedding)
+	_, err := s.db.Exec(ctx, query, story.ID, story.Title, story.URL, story.Score, story.By, story.Descendants, story.PostedAt, story.HNRank, story.Embedding, urlnorm.Normalize(story.URL), urlnorm.Host(story.URL))
 	return err
 }
 
-func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy string, topics []string, userID string, showHidden bool) ([]Story, error) {
+// BackfillURLKeys fills normalized_url and domain for stories ingested before
+// those columns existed. Unparseable URLs get an empty normalized_url so they
+// are not retried. Returns the number of stories updated.
+func (s *Store) BackfillURLKeys(ctx context.Context, batchSize int) (int, error) {
+	total := 0
+	for {
+		rows, err := s.db.Query(ctx, `
+			SELECT id, url FROM stories
+			WHERE url IS NOT NULL AND url != ''
+			  AND (normalized_url IS NULL OR (domain IS NULL AND normalized_url != ''))
+			LIMIT $1`, batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		batch := &pgx.Batch{}
+		for rows.Next() {
+			var id int64
+			var rawURL string
+			if err := rows.Scan(&id, &rawURL); err != nil {
+				rows.Close()
+				return total, err
+			}
+			batch.Queue(`UPDATE stories SET normalized_url = $1, domain = NULLIF($2, '') WHERE id = $3`, urlnorm.Normalize(rawURL), urlnorm.Host(rawURL), id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return total, err
+		}
+
+		if batch.Len() == 0 {
+			return total, nil
+		}
+
+		if err := s.db.SendBatch(ctx, batch).Close(); err != nil {
+			return total, err
+		}
+		total += batch.Len()
+	}
+}
+
+func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy string, topics []string, minPoints int, userID string, showHidden bool) ([]Story, error) {
 	// Base select — optionally LEFT JOIN user_interactions for logged-in users
-	selectCols := `s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary`
-	fromClause := `FROM stories s`
+	selectCols := `s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain, ` + previewColumns
+	fromClause := `FROM stories s` + previewJoin
 	hasUser := userID != ""
 
 	if hasUser {
-		selectCols += `, ui.is_read, ui.is_saved, ui.is_hidden`
+		selectCols += `, ui.is_read, ui.is_saved, ui.is_hidden, ` + newCommentsColumn
 		fromClause += ` LEFT JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1`
 	}
 
@@ -97,6 +157,9 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 		if !showHidden {
 			query += ` AND (ui.is_hidden IS NULL OR ui.is_hidden = FALSE)`
 		}
+
+		// Muted domains, keywords and authors never reach the client
+		query += muteFilterClause("$1")
 	}
 
 	// Multi-topic OR filter
@@ -110,6 +173,12 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 		query += ` AND s.search_vector @@ (` + strings.Join(tsqueryParts, " || ") + `)`
 	}
 
+	if minPoints > 0 {
+		query += fmt.Sprintf(` AND s.score >= $%d`, argID)
+		args = append(args, minPoints)
+		argID++
+	}
+
 	// Show HN filter
 	if sortStrategy == "show" {
 		query += ` AND s.title ILIKE 'Show HN:%'`
@@ -138,14 +207,17 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 	var stories []Story
 	for rows.Next() {
 		var story Story
+		var p Preview
+		dest := []interface{}{&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain,
+			&p.Description, &p.ImageURL, &p.SiteName, &p.Author, &p.PublishedAt, &p.FaviconURL}
 		if hasUser {
-			if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.IsRead, &story.IsSaved, &story.IsHidden); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary); err != nil {
-				return nil, err
-			}
+			dest = append(dest, &story.IsRead, &story.IsSaved, &story.IsHidden, &story.NewComments)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if !p.IsZero() {
+			story.Preview = &p
 		}
 		stories = append(stories, story)
 	}
@@ -153,9 +225,9 @@ func (s *Store) GetStories(ctx context.Context, limit, offset int, sortStrategy
 }
 
 func (s *Store) GetStory(ctx context.Context, id int) (*Story, error) {
-	query := `SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary FROM stories WHERE id = $1`
+	query := `SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, domain FROM stories WHERE id = $1`
 	var story Story
-	err := s.db.QueryRow(ctx, query, id).Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary)
+	err := s.db.QueryRow(ctx, query, id).Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain)
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +260,7 @@ type Comment struct {
 	Text     string    `json:"text"`
 	By       string    `json:"by"`
 	PostedAt time.Time `json:"time"`
+	IsNew    bool      `json:"is_new,omitempty"` // Posted since the user's last visit
 }
 
 type User struct {
@@ -252,13 +325,16 @@ func (s *Store) UpdateRanks(ctx context.Context, rankMap map[int]int) error {
 	return nil
 }
 
+// UpdateStorySummary caches the AI summary for a story. The embedding is
+// cleared so the embedding worker re-embeds the story with its summary.
 func (s *Store) UpdateStorySummary(ctx context.Context, id int, summary string) error {
-	query := `UPDATE stories SET summary = $1 WHERE id = $2`
+	query := `UPDATE stories SET summary = $1, embedding = NULL, embedding_attempted_at = NULL WHERE id = $2`
 	_, err := s.db.Exec(ctx, query, summary, id)
 	return err
 }
 
 // UpsertAuthUser creates or updates a user based on their Google ID.
+// Signing in cancels a pending account deletion.
 // Returns the user (with ID) after upsert.
 func (s *Store) UpsertAuthUser(ctx context.Context, googleID, email, name, avatarURL string) (*AuthUser, error) {
 	query := `
@@ -267,12 +343,13 @@ func (s *Store) UpsertAuthUser(ctx context.Context, googleID, email, name, avata
 		ON CONFLICT (google_id) DO UPDATE
 		SET email = EXCLUDED.email,
 			name = EXCLUDED.name,
-			avatar_url = EXCLUDED.avatar_url
-		RETURNING id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at
+			avatar_url = EXCLUDED.avatar_url,
+			deletion_requested_at = NULL
+		RETURNING id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at, deletion_requested_at
 	`
 	var user AuthUser
 	err := s.db.QueryRow(ctx, query, googleID, email, name, avatarURL).Scan(
-		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt,
+		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt, &user.DeletionRequestedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -282,10 +359,10 @@ func (s *Store) UpsertAuthUser(ctx context.Context, googleID, email, name, avata
 
 // GetAuthUser fetches a user by their UUID.
 func (s *Store) GetAuthUser(ctx context.Context, userID string) (*AuthUser, error) {
-	query := `SELECT id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at FROM auth_users WHERE id = $1`
+	query := `SELECT id, google_id, email, name, avatar_url, is_admin, COALESCE(gemini_api_key, ''), created_at, deletion_requested_at FROM auth_users WHERE id = $1`
 	var user AuthUser
 	err := s.db.QueryRow(ctx, query, userID).Scan(
-		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt,
+		&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt, &user.DeletionRequestedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -314,17 +391,85 @@ func (s *Store) UpsertInteraction(ctx context.Context, userID string, storyID in
 	return err
 }
 
+// StoryVisit is what a user had seen of a story's discussion on their last visit.
+type StoryVisit struct {
+	LastSeenCommentID int64
+	LastVisitedAt     time.Time
+}
+
+// GetStoryVisit returns the user's last visit to a story, or nil if they have never opened it.
+func (s *Store) GetStoryVisit(ctx context.Context, userID string, storyID int) (*StoryVisit, error) {
+	query := `
+		SELECT last_seen_comment_id, last_visited_at
+		FROM user_interactions
+		WHERE user_id = $1 AND story_id = $2 AND last_visited_at IS NOT NULL
+	`
+	var v StoryVisit
+	var commentID *int64
+	err := s.db.QueryRow(ctx, query, userID, storyID).Scan(&commentID, &v.LastVisitedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if commentID != nil {
+		v.LastSeenCommentID = *commentID
+	}
+	return &v, nil
+}
+
+// RecordStoryVisit advances the user's comment watermark for a story to
+// lastCommentID, the newest comment they were shown, or to the newest stored
+// comment when it is 0. The watermark never moves backwards. updated_at is
+// left alone so that visiting does not reorder the saved list.
+func (s *Store) RecordStoryVisit(ctx context.Context, userID string, storyID int, lastCommentID int64) error {
+	query := `
+		INSERT INTO user_interactions (user_id, story_id, last_seen_comment_id, last_visited_at, updated_at)
+		VALUES ($1, $2, COALESCE(NULLIF($3, 0), (SELECT MAX(id) FROM comments WHERE story_id = $2), 0), NOW(), NOW())
+		ON CONFLICT (user_id, story_id) DO UPDATE SET
+			last_seen_comment_id = GREATEST(user_interactions.last_seen_comment_id, EXCLUDED.last_seen_comment_id),
+			last_visited_at = NOW()
+	`
+	_, err := s.db.Exec(ctx, query, userID, storyID, lastCommentID)
+	return err
+}
+
+// SavedStoriesFilter narrows the saved list. Empty fields are ignored.
+type SavedStoriesFilter struct {
+	Tag    string // exact tag
+	Folder string // exact folder name
+	Query  string // full-text over title, tags and note
+}
+
 // GetSavedStories returns stories saved by a user, newest first.
-func (s *Store) GetSavedStories(ctx context.Context, userID string, limit, offset int) ([]Story, error) {
+func (s *Store) GetSavedStories(ctx context.Context, userID string, filter SavedStoriesFilter, limit, offset int) ([]Story, error) {
 	query := `
-		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, ui.is_read, ui.is_saved
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.domain,
+		       ui.is_read, ui.is_saved, ` + newCommentsColumn + `, ui.tags, ui.note, ui.folder
 		FROM stories s
 		INNER JOIN user_interactions ui ON s.id = ui.story_id AND ui.user_id = $1
-		WHERE ui.is_saved = TRUE
-		ORDER BY ui.updated_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := s.db.Query(ctx, query, userID, limit, offset)
+		WHERE ui.is_saved = TRUE` + muteFilterClause("$1")
+	args := []interface{}{userID}
+
+	if filter.Tag != "" {
+		args = append(args, normalizeTag(filter.Tag))
+		query += fmt.Sprintf(` AND $%d = ANY(ui.tags)`, len(args))
+	}
+	if filter.Folder != "" {
+		args = append(args, strings.TrimSpace(filter.Folder))
+		query += fmt.Sprintf(` AND ui.folder = $%d`, len(args))
+	}
+	if filter.Query != "" {
+		// Extend the story's title tsvector with the user's own tags and note
+		args = append(args, filter.Query)
+		query += fmt.Sprintf(` AND (s.search_vector || `+savedSearchVector+`) @@ plainto_tsquery('english', $%d)`, len(args))
+	}
+
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(` ORDER BY ui.updated_at DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +478,8 @@ func (s *Store) GetSavedStories(ctx context.Context, userID string, limit, offse
 	var stories []Story
 	for rows.Next() {
 		var story Story
-		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.IsRead, &story.IsSaved); err != nil {
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Domain,
+			&story.IsRead, &story.IsSaved, &story.NewComments, &story.Tags, &story.Note, &story.Folder); err != nil {
 			return nil, err
 		}
 		stories = append(stories, story)
@@ -342,16 +488,147 @@ func (s *Store) GetSavedStories(ctx context.Context, userID string, limit, offse
 }
 
 // SearchStories performs a semantic similarity search using a query embedding vector.
-func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, limit int) ([]Story, error) {
+// Stories less similar than minSimilarity are excluded, as are stories muted
+// by userID (empty for anonymous searches).
+func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, limit, offset int, minSimilarity float64, userID string) ([]Story, error) {
+	args := []interface{}{embedding, minSimilarity, limit, offset}
 	query := `
-		SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank,
-		       1 - (embedding <=> $1) as similarity
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain,
+		       1 - (s.embedding <=> $1) as similarity
+		FROM stories s
+		WHERE s.embedding IS NOT NULL AND 1 - (s.embedding <=> $1) > $2` + muteFilter(userID, &args) + `
+		ORDER BY s.embedding <=> $1
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		var similarity float64
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain, &similarity); err != nil {
+			return nil, err
+		}
+		story.Similarity = &similarity
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+// hybridCandidates is the least number of nearest neighbours a hybrid search
+// considers; maxHybridCandidates is the most HNSW can return (ef_search).
+const (
+	hybridCandidates    = 200
+	maxHybridCandidates = 1000
+)
+
+// HybridSearchStories ranks stories by a blend of vector similarity and
+// full-text rank, so exact keyword hits still surface when embeddings are weak.
+// A story matches if it is semantically close enough or matches the keywords.
+// Stories muted by userID (empty for anonymous searches) are excluded.
+//
+// Only the nearest neighbours and the keyword matches are scored, so both
+// the HNSW and the full-text index are used instead of scanning every story.
+func (s *Store) HybridSearchStories(ctx context.Context, queryText string, embedding pgvector.Vector, limit, offset int, minSimilarity float64, userID string) ([]Story, error) {
+	candidates := min(max(limit+offset, hybridCandidates), maxHybridCandidates)
+	args := []interface{}{embedding, queryText, minSimilarity, limit, offset, candidates}
+	// ts_rank_cd normalization 32 maps the rank into [0, 1) so it can be blended
+	// with cosine similarity. Weights favour the vector score.
+	query := `
+		WITH q AS (SELECT plainto_tsquery('english', $2) AS tsq),
+		candidates AS (
+			(SELECT id FROM stories WHERE embedding IS NOT NULL ORDER BY embedding <=> $1 LIMIT $6)
+			UNION
+			SELECT s.id FROM stories s, q WHERE s.search_vector @@ q.tsq
+		),
+		scored AS (
+			SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain,
+			       COALESCE(1 - (s.embedding <=> $1), 0) AS similarity,
+			       ts_rank_cd(s.search_vector, q.tsq, 32) AS keyword_rank,
+			       s.search_vector @@ q.tsq AS keyword_match
+			FROM candidates c
+			INNER JOIN stories s ON s.id = c.id, q
+			WHERE TRUE` + muteFilter(userID, &args) + `
+		)
+		SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, domain, similarity
+		FROM scored
+		WHERE similarity > $3 OR keyword_match
+		ORDER BY (0.7 * similarity + 0.3 * keyword_rank) DESC, posted_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	var stories []Story
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		// HNSW scans return at most ef_search rows, 40 by default
+		if _, err := tx.Exec(ctx, `SELECT set_config('hnsw.ef_search', $1, true)`, strconv.Itoa(candidates)); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var story Story
+			var similarity float64
+			if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain, &similarity); err != nil {
+				return err
+			}
+			story.Similarity = &similarity
+			stories = append(stories, story)
+		}
+		return rows.Err()
+	})
+	return stories, err
+}
+
+// GetStoriesWithoutEmbedding returns stories that still need an embedding:
+// the newest never-tried stories first, then failed ones, least recently
+// tried first.
+func (s *Store) GetStoriesWithoutEmbedding(ctx context.Context, limit int) ([]Story, error) {
+	query := `
+		SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, domain
 		FROM stories
-		WHERE embedding IS NOT NULL AND 1 - (embedding <=> $1) > 0.5
-		ORDER BY similarity DESC
-		LIMIT $2
+		WHERE embedding IS NULL
+		ORDER BY embedding_attempted_at ASC NULLS FIRST, posted_at DESC
+		LIMIT $1
 	`
-	rows, err := s.db.Query(ctx, query, embedding, limit)
+	return s.queryStories(ctx, query, limit)
+}
+
+// GetStoryEmbedding returns a story's embedding, or nil if it has not been embedded yet.
+func (s *Store) GetStoryEmbedding(ctx context.Context, id int) (*pgvector.Vector, error) {
+	var embedding *pgvector.Vector
+	err := s.db.QueryRow(ctx, `SELECT embedding FROM stories WHERE id = $1`, id).Scan(&embedding)
+	if err != nil {
+		return nil, err
+	}
+	return embedding, nil
+}
+
+// GetRelatedStories returns the nearest neighbours of the given embedding,
+// excluding the story itself, reposts of the same URL (see GetPastDiscussions)
+// and stories muted by userID (empty for anonymous readers).
+func (s *Store) GetRelatedStories(ctx context.Context, id int, embedding pgvector.Vector, limit int, minSimilarity float64, userID string) ([]Story, error) {
+	args := []interface{}{embedding, id, minSimilarity, limit}
+	query := `
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain,
+		       1 - (s.embedding <=> $1) as similarity
+		FROM stories s
+		LEFT JOIN stories t ON t.id = $2
+		WHERE s.id != $2
+		  AND s.embedding IS NOT NULL
+		  AND 1 - (s.embedding <=> $1) > $3
+		  AND (COALESCE(t.normalized_url, '') = '' OR s.normalized_url IS DISTINCT FROM t.normalized_url)` + muteFilter(userID, &args) + `
+		ORDER BY s.embedding <=> $1
+		LIMIT $4
+	`
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -361,7 +638,7 @@ func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, li
 	for rows.Next() {
 		var story Story
 		var similarity float64
-		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &similarity); err != nil {
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain, &similarity); err != nil {
 			return nil, err
 		}
 		story.Similarity = &similarity
@@ -370,6 +647,72 @@ func (s *Store) SearchStories(ctx context.Context, embedding pgvector.Vector, li
 	return stories, nil
 }
 
+// GetPastDiscussions returns earlier submissions of the same normalized URL, newest first.
+func (s *Store) GetPastDiscussions(ctx context.Context, id int, limit int) ([]Story, error) {
+	query := `
+		SELECT s.id, s.title, s.url, s.score, s.by, s.descendants, s.posted_at, s.created_at, s.hn_rank, s.summary, s.domain
+		FROM stories s
+		INNER JOIN stories t ON t.id = $1
+		WHERE s.normalized_url = t.normalized_url
+		  AND t.normalized_url != ''
+		  AND s.id != t.id
+		  AND s.posted_at < t.posted_at
+		ORDER BY s.posted_at DESC
+		LIMIT $2
+	`
+	return s.queryStories(ctx, query, id, limit)
+}
+
+// GetStoriesByURL returns all submissions whose URL normalizes to the same key
+// as rawURL, newest first. Used to detect duplicate submissions.
+func (s *Store) GetStoriesByURL(ctx context.Context, rawURL string, limit int) ([]Story, error) {
+	key := urlnorm.Normalize(rawURL)
+	if key == "" {
+		return nil, nil
+	}
+	query := `
+		SELECT id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, domain
+		FROM stories
+		WHERE normalized_url = $1
+		ORDER BY posted_at DESC
+		LIMIT $2
+	`
+	return s.queryStories(ctx, query, key, limit)
+}
+
+// queryStories runs a query selecting the standard story columns
+// (id, title, url, score, by, descendants, posted_at, created_at, hn_rank, summary, domain).
+func (s *Store) queryStories(ctx context.Context, query string, args ...interface{}) ([]Story, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var story Story
+		if err := rows.Scan(&story.ID, &story.Title, &story.URL, &story.Score, &story.By, &story.Descendants, &story.PostedAt, &story.CreatedAt, &story.HNRank, &story.Summary, &story.Domain); err != nil {
+			return nil, err
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+func (s *Store) UpdateStoryEmbedding(ctx context.Context, id int64, embedding pgvector.Vector) error {
+	query := `UPDATE stories SET embedding = $1, embedding_attempted_at = NULL WHERE id = $2`
+	_, err := s.db.Exec(ctx, query, embedding, id)
+	return err
+}
+
+// MarkEmbeddingFailed records a failed embedding attempt, moving the story
+// behind others still waiting for one.
+func (s *Store) MarkEmbeddingFailed(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, `UPDATE stories SET embedding_attempted_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
 type ChatMessage struct {
 	ID        int       `json:"id"`
 	UserID    string    `json:"user_id"`
@@ -437,7 +780,7 @@ func (s *Store) GetAppStats(ctx context.Context) (*AppStats, error) {
 func (s *Store) GetAllUsers(ctx context.Context) ([]*AuthUser, error) {
 	query := `
 		SELECT 
-			u.id, u.google_id, u.email, u.name, u.avatar_url, u.is_admin, COALESCE(u.gemini_api_key, ''), u.created_at,
+			u.id, u.google_id, u.email, u.name, u.avatar_url, u.is_admin, COALESCE(u.gemini_api_key, ''), u.created_at, u.deletion_requested_at,
 			COUNT(ui.story_id) FILTER (WHERE ui.is_read = TRUE) as total_views,
 			MAX(ui.updated_at) as last_seen
 		FROM auth_users u
@@ -455,7 +798,7 @@ func (s *Store) GetAllUsers(ctx context.Context) ([]*AuthUser, error) {
 	for rows.Next() {
 		var user AuthUser
 		if err := rows.Scan(
-			&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt,
+			&user.ID, &user.GoogleID, &user.Email, &user.Name, &user.AvatarURL, &user.IsAdmin, &user.GeminiAPIKey, &user.CreatedAt, &user.DeletionRequestedAt,
 			&user.TotalViews, &user.LastSeen,
 		); err != nil {
 			return nil, err
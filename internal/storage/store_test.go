@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStoriesWithoutEmbedding_FailedStoriesGoLast_Integration(t *testing.T) {
+	store := integrationStore(t)
+	ctx := context.Background()
+
+	// Posted in the future so no other pending story sorts ahead of them
+	failing := Story{ID: 990101, Title: "Embedding test failing story", By: "alice", PostedAt: time.Now().Add(48 * time.Hour)}
+	fresh := Story{ID: 990102, Title: "Embedding test fresh story", By: "bob", PostedAt: time.Now().Add(24 * time.Hour)}
+	for _, story := range []Story{failing, fresh} {
+		require.NoError(t, store.UpsertStory(ctx, story))
+	}
+	t.Cleanup(func() { store.db.Exec(ctx, `DELETE FROM stories WHERE id IN (990101, 990102)`) })
+
+	stories, err := store.GetStoriesWithoutEmbedding(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, stories, 1)
+	assert.Equal(t, failing.ID, stories[0].ID)
+
+	require.NoError(t, store.MarkEmbeddingFailed(ctx, failing.ID))
+	stories, err = store.GetStoriesWithoutEmbedding(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, stories, 1)
+	assert.Equal(t, fresh.ID, stories[0].ID)
+
+	// A new summary makes the story a fresh candidate again
+	require.NoError(t, store.UpdateStorySummary(ctx, int(failing.ID), "A summary"))
+	stories, err = store.GetStoriesWithoutEmbedding(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, stories, 1)
+	assert.Equal(t, failing.ID, stories[0].ID)
+}
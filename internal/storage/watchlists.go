@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	WatchKindTerm   = "term"
+	WatchKindDomain = "domain"
+	WatchKindAuthor = "author"
+
+	maxWatchesPerUser = 100
+)
+
+type WatchlistEntry struct {
+	ID        int       `json:"id"`
+	Kind      string    `json:"kind"`
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	ID           int64     `json:"id"`
+	StoryID      int64     `json:"story_id"`
+	StoryTitle   string    `json:"story_title"`
+	StoryURL     string    `json:"story_url"`
+	StoryBy      string    `json:"story_by"`
+	StorySummary *string   `json:"story_summary,omitempty"`
+	CommentID    *int64    `json:"comment_id,omitempty"`
+	CommentBy    *string   `json:"comment_by,omitempty"`
+	CommentText  *string   `json:"comment_text,omitempty"`
+	WatchKind    string    `json:"watch_kind"`
+	WatchPattern string    `json:"watch_pattern"`
+	IsRead       bool      `json:"is_read"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// normalizeWatch validates a watchlist entry. Terms, domains and authors share
+// the validation (and match regex) of the corresponding mute rule kinds;
+// domains also get a regex for finding them in comments.
+func normalizeWatch(kind, pattern string) (string, *string, error) {
+	switch kind {
+	case WatchKindTerm:
+		return normalizeFilter(FilterKindKeyword, pattern)
+	case WatchKindDomain:
+		domain, _, err := normalizeFilter(FilterKindDomain, pattern)
+		if err != nil {
+			return "", nil, err
+		}
+		re := domainMentionRegex(domain)
+		return domain, &re, nil
+	case WatchKindAuthor:
+		return normalizeFilter(FilterKindAuthor, pattern)
+	default:
+		return "", nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidFilter, kind)
+	}
+}
+
+// domainMentionRegex matches a domain or its subdomains in comment HTML,
+// whether linked or written out, but not longer names ending or starting with
+// it: a watch on x.com does not fire for fox.com or x.com.au. It is matched
+// case-insensitively and must mean the same to RE2 and Postgres.
+func domainMentionRegex(domain string) string {
+	return `(^|[^a-z0-9-])` + regexp.QuoteMeta(domain) + `([^a-z0-9.-]|\.([^a-z0-9]|$)|$)`
+}
+
+// GetWatchlist returns a user's watchlist entries, oldest first.
+func (s *Store) GetWatchlist(ctx context.Context, userID string) ([]WatchlistEntry, error) {
+	query := `SELECT id, kind, pattern, created_at FROM watchlist_entries WHERE user_id = $1 ORDER BY created_at ASC, id ASC`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []WatchlistEntry
+	for rows.Next() {
+		var e WatchlistEntry
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Pattern, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// CreateWatchlistEntry adds a watch. Adding an existing entry returns it unchanged.
+func (s *Store) CreateWatchlistEntry(ctx context.Context, userID, kind, pattern string) (*WatchlistEntry, error) {
+	pattern, matchRegex, err := normalizeWatch(kind, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM watchlist_entries WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count >= maxWatchesPerUser {
+		return nil, fmt.Errorf("%w: at most %d watchlist entries per user", ErrInvalidFilter, maxWatchesPerUser)
+	}
+
+	query := `
+		INSERT INTO watchlist_entries (user_id, kind, pattern, match_regex)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, kind, pattern) DO UPDATE SET match_regex = EXCLUDED.match_regex
+		RETURNING id, kind, pattern, created_at
+	`
+	var e WatchlistEntry
+	if err := s.db.QueryRow(ctx, query, userID, kind, pattern, matchRegex).Scan(&e.ID, &e.Kind, &e.Pattern, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// DeleteWatchlistEntry removes an entry owned by the user, along with its notifications.
+func (s *Store) DeleteWatchlistEntry(ctx context.Context, userID string, id int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM watchlist_entries WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchWatchlists records notifications for stories and comments ingested
+// since the given time that match any watchlist entry. It is idempotent:
+// an item alerts a user at most once. Returns the number of new notifications.
+func (s *Store) MatchWatchlists(ctx context.Context, since time.Time) (int64, error) {
+	// DISTINCT ON picks one matching entry per user and item
+	storyQuery := `
+		INSERT INTO notifications (user_id, watch_id, story_id)
+		SELECT DISTINCT ON (w.user_id, s.id) w.user_id, w.id, s.id
+		FROM stories s
+		INNER JOIN watchlist_entries w ON
+			(w.kind = 'term' AND s.title ~* w.match_regex)
+			OR (w.kind = 'domain' AND (s.domain = w.pattern OR right(s.domain, length(w.pattern) + 1) = '.' || w.pattern))
+			OR (w.kind = 'author' AND s.by = w.pattern)
+		WHERE s.created_at >= $1
+		ORDER BY w.user_id, s.id, w.id
+		ON CONFLICT (user_id, story_id, (COALESCE(comment_id, 0))) DO NOTHING
+	`
+	commentQuery := `
+		INSERT INTO notifications (user_id, watch_id, story_id, comment_id)
+		SELECT DISTINCT ON (w.user_id, c.id) w.user_id, w.id, c.story_id, c.id
+		FROM comments c
+		INNER JOIN watchlist_entries w ON
+			(w.kind = 'term' AND c.text ~* w.match_regex)
+			OR (w.kind = 'domain' AND c.text ~* w.match_regex)
+			OR (w.kind = 'author' AND c.by = w.pattern)
+		WHERE c.created_at >= $1
+		ORDER BY w.user_id, c.id, w.id
+		ON CONFLICT (user_id, story_id, (COALESCE(comment_id, 0))) DO NOTHING
+	`
+
+	var total int64
+	for _, query := range []string{storyQuery, commentQuery} {
+		tag, err := s.db.Exec(ctx, query, since)
+		if err != nil {
+			return total, err
+		}
+		total += tag.RowsAffected()
+	}
+	return total, nil
+}
+
+// GetNotifications returns a user's notifications, newest first, leaving out
+// stories the user has muted since they were matched.
+func (s *Store) GetNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]Notification, error) {
+	query := `
+		SELECT n.id, n.story_id, s.title, COALESCE(s.url, ''), COALESCE(s.by, ''), s.summary, n.comment_id, c.by, left(c.text, 500),
+		       w.kind, w.pattern, n.is_read, n.created_at
+		FROM notifications n
+		INNER JOIN stories s ON s.id = n.story_id
+		INNER JOIN watchlist_entries w ON w.id = n.watch_id
+		LEFT JOIN comments c ON c.id = n.comment_id
+		WHERE n.user_id = $1 AND (NOT $2 OR n.is_read = FALSE)` + muteFilterClause("$1") + `
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3 OFFSET $4
+	`
+	return s.queryNotifications(ctx, query, userID, unreadOnly, limit, offset)
+}
+
+// GetAllNotifications returns all of a user's notifications, newest first,
+// including those on muted stories, for data exports.
+func (s *Store) GetAllNotifications(ctx context.Context, userID string) ([]Notification, error) {
+	query := `
+		SELECT n.id, n.story_id, s.title, COALESCE(s.url, ''), COALESCE(s.by, ''), s.summary, n.comment_id, c.by, left(c.text, 500),
+		       w.kind, w.pattern, n.is_read, n.created_at
+		FROM notifications n
+		INNER JOIN stories s ON s.id = n.story_id
+		INNER JOIN watchlist_entries w ON w.id = n.watch_id
+		LEFT JOIN comments c ON c.id = n.comment_id
+		WHERE n.user_id = $1
+		ORDER BY n.created_at DESC, n.id DESC
+	`
+	return s.queryNotifications(ctx, query, userID)
+}
+
+func (s *Store) queryNotifications(ctx context.Context, query string, args ...interface{}) ([]Notification, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []Notification
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.StoryID, &n.StoryTitle, &n.StoryURL, &n.StoryBy, &n.StorySummary, &n.CommentID, &n.CommentBy, &n.CommentText,
+			&n.WatchKind, &n.WatchPattern, &n.IsRead, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// CountUnreadNotifications returns the number of unread notifications for a user.
+func (s *Store) CountUnreadNotifications(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM notifications n
+		INNER JOIN stories s ON s.id = n.story_id
+		WHERE n.user_id = $1 AND n.is_read = FALSE` + muteFilterClause("$1")
+	err := s.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkNotificationsRead sets the read state of the given notifications, or of
+// all the user's notifications when ids is empty.
+func (s *Store) MarkNotificationsRead(ctx context.Context, userID string, ids []int64, isRead bool) error {
+	if len(ids) == 0 {
+		_, err := s.db.Exec(ctx, `UPDATE notifications SET is_read = $2 WHERE user_id = $1 AND is_read != $2`, userID, isRead)
+		return err
+	}
+	_, err := s.db.Exec(ctx, `UPDATE notifications SET is_read = $3 WHERE user_id = $1 AND id = ANY($2)`, userID, ids, isRead)
+	return err
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeWatch(t *testing.T) {
+	pattern, re, err := normalizeWatch(WatchKindDomain, " WWW.X.com ")
+	assert.NoError(t, err)
+	assert.Equal(t, "x.com", pattern)
+	assert.NotNil(t, re)
+
+	pattern, re, err = normalizeWatch(WatchKindTerm, "postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", pattern)
+	assert.True(t, regexp.MustCompile("(?i)"+*re).MatchString("Postgres 17 released"))
+
+	pattern, re, err = normalizeWatch(WatchKindAuthor, "dang")
+	assert.NoError(t, err)
+	assert.Equal(t, "dang", pattern)
+	assert.Nil(t, re)
+
+	_, _, err = normalizeWatch("color", "red")
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+
+	_, _, err = normalizeWatch(WatchKindDomain, "localhost")
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
+
+func TestDomainMentionRegex(t *testing.T) {
+	matcher := regexp.MustCompile("(?i)" + domainMentionRegex("x.com"))
+
+	for _, text := range []string{
+		"x.com",
+		"Posted on X.com yesterday.",
+		`See <a href="https:&#x2F;&#x2F;x.com&#x2F;user&#x2F;status&#x2F;1" rel="nofollow">https:&#x2F;&#x2F;x.com&#x2F;user</a>`,
+		"mirrored at https://mobile.x.com/user",
+		"I read it on x.com.",
+	} {
+		assert.True(t, matcher.MatchString(text), text)
+	}
+	for _, text := range []string{
+		"Fox.com covered it",
+		`<a href="https:&#x2F;&#x2F;fox.com&#x2F;news">`,
+		"x.company is a different site",
+		"x.com.au is a different site",
+		"my-x.com is a different site",
+	} {
+		assert.False(t, matcher.MatchString(text), text)
+	}
+}
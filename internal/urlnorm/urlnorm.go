@@ -0,0 +1,113 @@
+// Package urlnorm normalizes story URLs so that reposts of the same page can
+// be matched regardless of tracking parameters, scheme or mobile subdomains.
+package urlnorm
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that never change the page content.
+// Any parameter starting with "utm_" is also dropped.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref_src": true,
+	"igshid":  true,
+}
+
+// mirrorLabels are subdomain labels that serve the same content as the host
+// without them, e.g. www.example.com, m.example.com, en.m.wikipedia.org.
+var mirrorLabels = map[string]bool{
+	"www":    true,
+	"m":      true,
+	"mobile": true,
+	"amp":    true,
+}
+
+// Normalize returns a canonical key for a URL, or "" if it cannot be parsed
+// or is not http(s). The key drops the scheme, fragment, default ports,
+// www/mobile subdomains, tracking parameters and trailing slashes, and sorts
+// the remaining query parameters. It is intended for matching, not fetching.
+func Normalize(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return ""
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return ""
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+
+	key := host + path
+	if len(query) > 0 {
+		key += "?" + encodeSorted(query)
+	}
+	return key
+}
+
+// Host returns the lowercased host of a URL with www/mobile subdomains removed,
+// or "" if the URL has no host.
+func Host(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return normalizeHost(u.Hostname())
+}
+
+// Domain normalizes a user-supplied site name such as "www.Example.com" the
+// same way Host does, so it can be compared against stories.domain.
+func Domain(site string) string {
+	site = strings.TrimSpace(site)
+	if !strings.Contains(site, "://") {
+		site = "http://" + site
+	}
+	return Host(site)
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	labels := strings.Split(host, ".")
+
+	kept := labels[:0]
+	for i, label := range labels {
+		// Only strip subdomains: at least two labels must follow (keep "m.com")
+		if mirrorLabels[label] && len(labels)-i > 2 {
+			continue
+		}
+		kept = append(kept, label)
+	}
+	return strings.Join(kept, ".")
+}
+
+// encodeSorted is url.Values.Encode with values also sorted, so parameter
+// order never affects the key.
+func encodeSorted(v url.Values) string {
+	for _, vals := range v {
+		sort.Strings(vals)
+	}
+	return v.Encode()
+}
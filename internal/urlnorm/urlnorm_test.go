@@ -0,0 +1,50 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"https://www.example.com/post/", "example.com/post"},
+		{"http://example.com/post", "example.com/post"},
+		{"https://m.example.com/post?utm_source=hn&utm_medium=x", "example.com/post"},
+		{"https://mobile.twitter.com/user/status/1", "twitter.com/user/status/1"},
+		{"https://example.com/post#comments", "example.com/post"},
+		{"https://Example.COM:443/Post", "example.com/Post"},
+		{"https://example.com:8080/a", "example.com:8080/a"},
+		{"https://example.com/?b=2&a=1&fbclid=abc", "example.com?a=1&b=2"},
+		{"https://www.youtube.com/watch?v=abc&utm_campaign=z", "youtube.com/watch?v=abc"},
+		{"https://m.com/page", "m.com/page"},
+		{"", ""},
+		{"ftp://example.com/file", ""},
+		{"not a url", ""},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, Normalize(c.in), c.in)
+	}
+}
+
+func TestNormalize_RepostsMatch(t *testing.T) {
+	a := Normalize("https://www.example.com/2019/great-essay/?utm_source=hackernews")
+	b := Normalize("http://example.com/2019/great-essay")
+	assert.Equal(t, a, b)
+}
+
+func TestHost(t *testing.T) {
+	assert.Equal(t, "nytimes.com", Host("https://www.nytimes.com/2024/01/01/tech.html"))
+	assert.Equal(t, "en.wikipedia.org", Host("https://en.m.wikipedia.org/wiki/Go"))
+	assert.Equal(t, "", Host(""))
+}
+
+func TestDomain(t *testing.T) {
+	assert.Equal(t, "example.com", Domain("www.Example.com"))
+	assert.Equal(t, "blog.example.com", Domain("https://blog.example.com/"))
+	assert.Equal(t, "", Domain(""))
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stories_normalized_url;
+ALTER TABLE stories DROP COLUMN IF EXISTS normalized_url;
@@ -0,0 +1,5 @@
+-- Canonical URL key (see internal/urlnorm) used to find reposts of the same page.
+-- Existing rows are backfilled by the ingest service on startup (Store.BackfillURLKeys).
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS normalized_url TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_stories_normalized_url ON stories(normalized_url) WHERE normalized_url IS NOT NULL;
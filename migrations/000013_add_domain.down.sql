@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stories_domain_posted_at;
+ALTER TABLE stories DROP COLUMN IF EXISTS domain;
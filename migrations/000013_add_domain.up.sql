@@ -0,0 +1,5 @@
+-- Site a story links to (see urlnorm.Host), e.g. "github.com" or "en.wikipedia.org".
+-- NULL for text posts. Existing rows are backfilled by the ingest service on startup.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS domain TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_stories_domain_posted_at ON stories(domain, posted_at DESC) WHERE domain IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Per-user mute rules applied server-side in GetStories.
+-- kind: 'domain' (site and its subdomains), 'keyword' (whole word in title),
+--       'regex' (case-insensitive title regex), 'author' (HN username)
+-- match_regex is the Postgres regex used for 'keyword' and 'regex' rules.
+CREATE TABLE IF NOT EXISTS user_filters (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('domain', 'keyword', 'regex', 'author')),
+    pattern TEXT NOT NULL,
+    match_regex TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, kind, pattern)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_filters_user ON user_filters(user_id);
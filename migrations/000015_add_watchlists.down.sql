@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_comments_created_at;
+DROP INDEX IF EXISTS idx_stories_created_at;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS watchlist_entries;
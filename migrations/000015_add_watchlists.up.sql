@@ -0,0 +1,34 @@
+-- Per-user watchlists: alert when new stories/comments mention something.
+-- kind: 'term' (whole word in story title or comment text), 'domain' (story
+--       site, or a link to it in a comment), 'author' (HN username)
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('term', 'domain', 'author')),
+    pattern TEXT NOT NULL,
+    match_regex TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, kind, pattern)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_user ON watchlist_entries(user_id);
+
+-- Alerts recorded by the ingest service after each run.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES auth_users(id) ON DELETE CASCADE,
+    watch_id INTEGER NOT NULL REFERENCES watchlist_entries(id) ON DELETE CASCADE,
+    story_id BIGINT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    comment_id BIGINT REFERENCES comments(id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One alert per user per item, however many entries match it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_item ON notifications(user_id, story_id, (COALESCE(comment_id, 0)));
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE is_read = FALSE;
+
+-- Watchlist matching scans recently ingested rows
+CREATE INDEX IF NOT EXISTS idx_stories_created_at ON stories(created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments(created_at);
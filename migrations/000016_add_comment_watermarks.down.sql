@@ -0,0 +1,3 @@
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS last_visited_at;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS last_seen_descendants;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS last_seen_comment_id;
@@ -0,0 +1,5 @@
+-- What a user had already seen of a story's discussion on their last visit.
+-- HN item IDs increase monotonically, so comments with id > last_seen_comment_id are new.
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS last_seen_comment_id BIGINT;
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS last_seen_descendants INT;
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS last_visited_at TIMESTAMP WITH TIME ZONE;
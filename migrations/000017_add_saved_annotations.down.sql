@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_interactions_folder;
+DROP INDEX IF EXISTS idx_user_interactions_tags;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS folder;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS note;
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,7 @@
+-- User-defined organisation of saved stories
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS note TEXT;
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS folder TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_user_interactions_tags ON user_interactions USING GIN(tags);
+CREATE INDEX IF NOT EXISTS idx_user_interactions_folder ON user_interactions(user_id, folder) WHERE folder IS NOT NULL;
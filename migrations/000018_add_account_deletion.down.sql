@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_auth_users_deletion;
+ALTER TABLE auth_users DROP COLUMN IF EXISTS deletion_requested_at;
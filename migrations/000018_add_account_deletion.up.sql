@@ -0,0 +1,4 @@
+-- Accounts pending deletion are purged by the ingest service after a grace period.
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_auth_users_deletion ON auth_users(deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE auth_users DROP COLUMN IF EXISTS feed_token;
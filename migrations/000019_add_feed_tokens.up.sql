@@ -0,0 +1,2 @@
+-- Secret token authenticating a user's private feed URLs
+ALTER TABLE auth_users ADD COLUMN IF NOT EXISTS feed_token TEXT UNIQUE;
@@ -0,0 +1,9 @@
+-- Single-row counter bumped after every ingestion run; API servers use it to
+-- invalidate cached responses.
+CREATE TABLE IF NOT EXISTS ingest_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO ingest_state (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
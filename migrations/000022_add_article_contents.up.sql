@@ -0,0 +1,17 @@
+-- Extracted article content, shared by reading mode and the summary worker.
+-- fetched_at is the last fetch attempt; a failed refresh keeps the previous
+-- content so dead links still open in reading mode.
+CREATE TABLE IF NOT EXISTS article_contents (
+    story_id BIGINT PRIMARY KEY REFERENCES stories(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    content_html TEXT NOT NULL DEFAULT '',
+    content_text TEXT NOT NULL DEFAULT '',
+    can_iframe BOOLEAN NOT NULL DEFAULT TRUE,
+    status TEXT NOT NULL,
+    http_status INT,
+    error TEXT,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_contents_fetched_at ON article_contents(fetched_at);
@@ -0,0 +1,7 @@
+ALTER TABLE article_contents
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS image_url,
+    DROP COLUMN IF EXISTS site_name,
+    DROP COLUMN IF EXISTS author,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS favicon_url;
@@ -0,0 +1,10 @@
+-- Open Graph / Twitter card preview metadata and favicon of a story's page,
+-- captured with the article content and shown in story lists. Like the
+-- content, it is kept when a later refresh fails.
+ALTER TABLE article_contents
+    ADD COLUMN IF NOT EXISTS description TEXT,
+    ADD COLUMN IF NOT EXISTS image_url TEXT,
+    ADD COLUMN IF NOT EXISTS site_name TEXT,
+    ADD COLUMN IF NOT EXISTS author TEXT,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS favicon_url TEXT;
@@ -0,0 +1,5 @@
+ALTER TABLE article_contents
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS archive_url;
+
+DROP TABLE IF EXISTS link_checks;
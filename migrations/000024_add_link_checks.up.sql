@@ -0,0 +1,20 @@
+-- Periodic health checks of story URLs. status is ok, error (failing, may be
+-- transient) or dead (gone, or failing for several checks in a row).
+CREATE TABLE IF NOT EXISTS link_checks (
+    story_id BIGINT PRIMARY KEY REFERENCES stories(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    http_status INT,
+    error TEXT,
+    failures INT NOT NULL DEFAULT 0, -- Consecutive failed checks
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_ok_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_checks_checked_at ON link_checks(checked_at);
+
+-- Where cached article content came from: the origin, or an archived
+-- snapshot at archive_url once the origin is gone.
+ALTER TABLE article_contents
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'origin',
+    ADD COLUMN IF NOT EXISTS archive_url TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE article_contents
+    DROP COLUMN IF EXISTS outcome;
@@ -0,0 +1,4 @@
+-- What the latest fetch found: the article, or a paywall, login_wall,
+-- captcha or consent page in front of it.
+ALTER TABLE article_contents
+    ADD COLUMN IF NOT EXISTS outcome TEXT NOT NULL DEFAULT 'article';
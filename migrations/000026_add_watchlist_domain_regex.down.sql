@@ -0,0 +1 @@
+UPDATE watchlist_entries SET match_regex = NULL WHERE kind = 'domain';
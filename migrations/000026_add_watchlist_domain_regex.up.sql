@@ -0,0 +1,5 @@
+-- Domain watches match comments by regex (see domainMentionRegex) instead of
+-- substring, so a watch on x.com no longer fires for fox.com.
+UPDATE watchlist_entries
+SET match_regex = '(^|[^a-z0-9-])' || replace(pattern, '.', '\.') || '([^a-z0-9.-]|\.([^a-z0-9]|$)|$)'
+WHERE kind = 'domain';
@@ -0,0 +1 @@
+ALTER TABLE user_interactions ADD COLUMN IF NOT EXISTS last_seen_descendants INT;
@@ -0,0 +1,3 @@
+-- New-comment counts now come from last_seen_comment_id alone, so lists and
+-- the story view agree on which comments are new.
+ALTER TABLE user_interactions DROP COLUMN IF EXISTS last_seen_descendants;
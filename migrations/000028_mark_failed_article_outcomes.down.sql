@@ -0,0 +1 @@
+UPDATE article_contents SET outcome = 'article' WHERE outcome = 'error';
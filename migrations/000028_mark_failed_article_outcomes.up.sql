@@ -0,0 +1,4 @@
+-- Failed fetches that were not walls were recorded as 'article'; they are
+-- now recorded as 'error'. Walls are always recorded as failures.
+UPDATE article_contents SET outcome = 'error'
+WHERE status = 'failed' AND outcome = 'article';
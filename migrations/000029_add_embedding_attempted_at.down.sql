@@ -0,0 +1 @@
+ALTER TABLE stories DROP COLUMN IF EXISTS embedding_attempted_at;
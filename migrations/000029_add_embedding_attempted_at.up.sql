@@ -0,0 +1,4 @@
+-- When the embedding worker last failed to embed a story. Failed stories are
+-- retried after those never tried, so one that keeps failing cannot hold up
+-- the batch.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS embedding_attempted_at TIMESTAMPTZ;
@@ -0,0 +1 @@
+ALTER TABLE article_contents DROP COLUMN IF EXISTS archive_missed_at;
@@ -0,0 +1,3 @@
+-- When the archive last had no usable snapshot of the row's URL, so reading
+-- mode does not ask it again on every request for a failing link.
+ALTER TABLE article_contents ADD COLUMN IF NOT EXISTS archive_missed_at TIMESTAMPTZ;